@@ -43,6 +43,16 @@ func main() {
 		cfg.SCMProvider = os.Getenv("DRONE_REPO_SCM")
 	}
 
+	// Fallback to DRONE_BUILD_STARTED for the start time of finalized checks
+	if cfg.StatusStarted == "" {
+		cfg.StatusStarted = os.Getenv("DRONE_BUILD_STARTED")
+	}
+
+	// Fallback to DRONE_SOURCE_BRANCH for finding the pull request
+	if cfg.SourceBranch == "" {
+		cfg.SourceBranch = os.Getenv("DRONE_SOURCE_BRANCH")
+	}
+
 	// Validate required fields after fallbacks
 	if cfg.SCMProvider == "" {
 		logrus.Fatal("SCM_PROVIDER is required (or set DRONE_REPO_SCM)")
@@ -85,7 +95,14 @@ func main() {
 		"harness_account_id": cfg.HarnessAccountID,
 		"harness_org_id":     cfg.HarnessOrgID,
 		"harness_project_id": cfg.HarnessProjectID,
-		"comments_file":      cfg.CommentsFile,
+		"azure_organization": cfg.AzureOrganization,
+		"azure_project":      cfg.AzureProject,
+		"comments_file":      cfg.CommentsFiles,
+		"review_event":       cfg.ReviewEvent,
+		"github_app_id":      cfg.GitHubAppID,
+		"oauth_grant_type":   cfg.OAuthGrantType,
+		"auth_mode":          cfg.AuthMode,
+		"username":           cfg.Username,
 		"file_path":          cfg.FilePath,
 		"line":               cfg.Line,
 		"token":              tokenPreview,
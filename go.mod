@@ -3,10 +3,12 @@ module github.com/abhinav-harness/comment-plugin
 go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/drone/go-scm v1.38.4
 	github.com/joho/godotenv v1.5.1
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
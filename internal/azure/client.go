@@ -0,0 +1,420 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const apiVersion = "7.1"
+
+// Config holds configuration for the Azure DevOps client
+type Config struct {
+	Endpoint     string
+	Token        string
+	Username     string // Sent with the token for basic auth, empty for personal access tokens
+	Bearer       bool   // Send the token as a bearer token, e.g. a Microsoft Entra ID access token
+	Organization string
+	Project      string
+}
+
+// Client is a specialized client for Azure DevOps Repos
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	baseURL    string
+	log        *logrus.Entry
+}
+
+// ThreadInput describes a pull request comment thread. Leave FilePath empty
+// for a general comment; columns are optional.
+type ThreadInput struct {
+	Body        string
+	FilePath    string
+	LineStart   int
+	LineEnd     int
+	StartColumn int
+	EndColumn   int
+	Status      string
+}
+
+// Thread is an existing pull request comment thread
+type Thread struct {
+	ID     int
+	Status string
+	// Content is the text of the thread's first comment
+	Content string
+}
+
+// StatusInput describes a pull request or commit status
+type StatusInput struct {
+	State       string
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+// NewClient creates a new Azure DevOps client
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+	if cfg.Organization == "" {
+		return nil, fmt.Errorf("organization is required")
+	}
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+
+	baseURL := cfg.Endpoint
+	if baseURL == "" {
+		baseURL = "https://dev.azure.com"
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	log := logrus.WithField("component", "azure")
+
+	log.WithFields(logrus.Fields{
+		"base_url":     baseURL,
+		"organization": cfg.Organization,
+		"project":      cfg.Project,
+	}).Info("initialized Azure DevOps client")
+
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		log:        log,
+	}, nil
+}
+
+// CreateComment creates a general comment thread on a pull request
+func (c *Client) CreateComment(ctx context.Context, repo string, prNumber int, body string) error {
+	_, err := c.CreateThread(ctx, repo, prNumber, ThreadInput{Body: body})
+	return err
+}
+
+// CreateInlineComment creates a comment thread anchored to a file line
+func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber int, filePath string, line int, body string) error {
+	_, err := c.CreateThread(ctx, repo, prNumber, ThreadInput{
+		Body:      body,
+		FilePath:  filePath,
+		LineStart: line,
+		LineEnd:   line,
+	})
+	return err
+}
+
+// CreateThread creates a pull request comment thread and returns its ID
+func (c *Client) CreateThread(ctx context.Context, repo string, prNumber int, input ThreadInput) (int, error) {
+	c.log.WithFields(logrus.Fields{
+		"repo":       repo,
+		"pr_number":  prNumber,
+		"file_path":  input.FilePath,
+		"line_start": input.LineStart,
+		"line_end":   input.LineEnd,
+	}).Info("creating PR thread")
+
+	path := c.apiPath(repo, fmt.Sprintf("pullRequests/%d/threads", prNumber))
+
+	status := input.Status
+	if status == "" {
+		status = "active"
+	}
+
+	payload := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{
+				"parentCommentId": 0,
+				"content":         input.Body,
+				"commentType":     "text",
+			},
+		},
+		"status": status,
+	}
+	if input.FilePath != "" {
+		payload["threadContext"] = threadContext(input)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return 0, fmt.Errorf("failed to create thread: %w", err)
+	}
+
+	var thread struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+		return 0, err
+	}
+
+	c.log.WithField("thread_id", thread.ID).Info("created PR thread successfully")
+	return thread.ID, nil
+}
+
+// ListThreads returns the comment threads of a pull request, skipping
+// deleted ones
+func (c *Client) ListThreads(ctx context.Context, repo string, prNumber int) ([]Thread, error) {
+	path := c.apiPath(repo, fmt.Sprintf("pullRequests/%d/threads", prNumber))
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	var result struct {
+		Value []struct {
+			ID        int    `json:"id"`
+			Status    string `json:"status"`
+			IsDeleted bool   `json:"isDeleted"`
+			Comments  []struct {
+				Content string `json:"content"`
+			} `json:"comments"`
+		} `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	threads := make([]Thread, 0, len(result.Value))
+	for _, t := range result.Value {
+		if t.IsDeleted {
+			continue
+		}
+		thread := Thread{ID: t.ID, Status: t.Status}
+		if len(t.Comments) > 0 {
+			thread.Content = t.Comments[0].Content
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// UpdateThreadStatus sets the status of an existing thread
+// (active, fixed, wontFix, closed, byDesign, pending)
+func (c *Client) UpdateThreadStatus(ctx context.Context, repo string, prNumber, threadID int, status string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"thread_id": threadID,
+		"status":    status,
+	}).Info("updating PR thread status")
+
+	path := c.apiPath(repo, fmt.Sprintf("pullRequests/%d/threads/%d", prNumber, threadID))
+
+	resp, err := c.do(ctx, http.MethodPatch, path, map[string]interface{}{"status": status})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to update thread status: %w", err)
+	}
+	return nil
+}
+
+// UpdateDescription replaces the description of a pull request
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	path := c.apiPath(repo, fmt.Sprintf("pullRequests/%d", prNumber))
+	resp, err := c.do(ctx, http.MethodPatch, path, map[string]interface{}{"description": description})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// CreatePRStatus creates a status on a pull request
+func (c *Client) CreatePRStatus(ctx context.Context, repo string, prNumber int, input StatusInput) error {
+	path := c.apiPath(repo, fmt.Sprintf("pullRequests/%d/statuses", prNumber))
+	return c.createStatus(ctx, path, input)
+}
+
+// CreateCommitStatus creates a status on a commit
+func (c *Client) CreateCommitStatus(ctx context.Context, repo, commitSHA string, input StatusInput) error {
+	path := c.apiPath(repo, fmt.Sprintf("commits/%s/statuses", commitSHA))
+	return c.createStatus(ctx, path, input)
+}
+
+func (c *Client) createStatus(ctx context.Context, path string, input StatusInput) error {
+	c.log.WithFields(logrus.Fields{
+		"state":   input.State,
+		"context": input.Context,
+	}).Info("creating status")
+
+	genre, name := splitContext(input.Context)
+
+	payload := map[string]interface{}{
+		"state":       mapState(input.State),
+		"description": input.Description,
+		"context": map[string]string{
+			"genre": genre,
+			"name":  name,
+		},
+	}
+	if input.TargetURL != "" {
+		payload["targetUrl"] = input.TargetURL
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to create status: %w", err)
+	}
+
+	c.log.WithField("context", input.Context).Info("created status successfully")
+	return nil
+}
+
+// threadContext anchors a thread to the right (new) side of the diff.
+// Azure DevOps paths are absolute from the repository root and offsets are
+// 1-based columns; without columns the thread is anchored to the line starts.
+func threadContext(input ThreadInput) map[string]interface{} {
+	lineEnd := input.LineEnd
+	if lineEnd < input.LineStart {
+		lineEnd = input.LineStart
+	}
+
+	startOffset, endOffset := 1, 1
+	if input.StartColumn > 0 {
+		startOffset = input.StartColumn
+	}
+	if input.EndColumn > 0 {
+		// The end offset is exclusive
+		endOffset = input.EndColumn + 1
+	}
+
+	return map[string]interface{}{
+		"filePath":       "/" + strings.TrimPrefix(input.FilePath, "/"),
+		"rightFileStart": map[string]int{"line": input.LineStart, "offset": startOffset},
+		"rightFileEnd":   map[string]int{"line": lineEnd, "offset": endOffset},
+	}
+}
+
+// splitContext splits a status context like "ci/lint" into genre and name
+func splitContext(context string) (string, string) {
+	if i := strings.LastIndex(context, "/"); i > 0 {
+		return context[:i], context[i+1:]
+	}
+	return "", context
+}
+
+func (c *Client) apiPath(repo, suffix string) string {
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+
+	return fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/%s?%s",
+		c.baseURL,
+		url.PathEscape(c.config.Organization),
+		url.PathEscape(c.config.Project),
+		url.PathEscape(repo),
+		suffix,
+		query.Encode(),
+	)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(jsonBody)
+		c.log.WithField("payload", string(jsonBody)).Debug("request payload")
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"method": method,
+		"url":    path,
+	}).Debug("making API request")
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	if c.config.Bearer {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	} else {
+		// Personal access tokens are sent as the basic auth password
+		req.SetBasicAuth(c.config.Username, c.config.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.log.WithError(err).Error("API request failed")
+		return nil, err
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"status_code": resp.StatusCode,
+		"status":      resp.Status,
+	}).Debug("received API response")
+
+	return resp, nil
+}
+
+func (c *Client) checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	c.log.WithFields(logrus.Fields{
+		"status_code":   resp.StatusCode,
+		"response_body": string(body),
+	}).Error("API request returned error")
+
+	return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+}
+
+func mapState(state string) string {
+	switch strings.ToLower(state) {
+	case "success":
+		return "succeeded"
+	case "failure", "failed":
+		return "failed"
+	case "error":
+		return "error"
+	case "pending", "running":
+		return "pending"
+	default:
+		return "notSet"
+	}
+}
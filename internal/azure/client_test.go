@@ -0,0 +1,275 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recorded captures the last request received by the test server
+type recorded struct {
+	method string
+	path   string
+	query  string
+	user   string
+	pass   string
+	body   map[string]interface{}
+}
+
+func newTestServer(t *testing.T, status int, response string) (*httptest.Server, *recorded) {
+	t.Helper()
+	rec := &recorded{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.method = r.Method
+		rec.path = r.URL.Path
+		rec.query = r.URL.RawQuery
+		rec.user, rec.pass, _ = r.BasicAuth()
+		rec.body = nil
+		_ = json.NewDecoder(r.Body).Decode(&rec.body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func newTestClient(t *testing.T, endpoint string) *Client {
+	t.Helper()
+	c, err := NewClient(Config{Endpoint: endpoint, Token: "pat", Organization: "org", Project: "proj"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return c
+}
+
+func TestNewClient(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	if _, err := NewClient(Config{Token: "pat", Organization: "org", Project: "proj"}); err != nil {
+		t.Errorf("NewClient failed: %v", err)
+	}
+	if _, err := NewClient(Config{Organization: "org", Project: "proj"}); err == nil {
+		t.Error("NewClient should fail without token")
+	}
+	if _, err := NewClient(Config{Token: "pat", Project: "proj"}); err == nil {
+		t.Error("NewClient should fail without organization")
+	}
+}
+
+func TestCreateInlineThread(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusOK, `{"id": 42}`)
+	c := newTestClient(t, srv.URL)
+
+	id, err := c.CreateThread(context.Background(), "my-repo", 7, ThreadInput{
+		Body:        "fix this",
+		FilePath:    "src/main.go",
+		LineStart:   10,
+		LineEnd:     12,
+		StartColumn: 3,
+		EndColumn:   8,
+		Status:      "pending",
+	})
+	if err != nil {
+		t.Fatalf("CreateThread failed: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("thread id = %d, want 42", id)
+	}
+
+	if rec.method != http.MethodPost || rec.path != "/org/proj/_apis/git/repositories/my-repo/pullRequests/7/threads" {
+		t.Errorf("unexpected request %s %s", rec.method, rec.path)
+	}
+	if rec.query != "api-version="+apiVersion {
+		t.Errorf("unexpected query %q", rec.query)
+	}
+	if rec.user != "" || rec.pass != "pat" {
+		t.Errorf("expected PAT as basic auth password, got %q:%q", rec.user, rec.pass)
+	}
+	if rec.body["status"] != "pending" {
+		t.Errorf("status = %v, want pending", rec.body["status"])
+	}
+
+	ctx, ok := rec.body["threadContext"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("missing threadContext in %v", rec.body)
+	}
+	if ctx["filePath"] != "/src/main.go" {
+		t.Errorf("filePath = %v, want /src/main.go", ctx["filePath"])
+	}
+	start := ctx["rightFileStart"].(map[string]interface{})
+	end := ctx["rightFileEnd"].(map[string]interface{})
+	if start["line"] != 10.0 || start["offset"] != 3.0 || end["line"] != 12.0 || end["offset"] != 9.0 {
+		t.Errorf("unexpected range %v - %v", start, end)
+	}
+}
+
+func TestCreateGeneralThread(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusOK, `{"id": 1}`)
+	c := newTestClient(t, srv.URL)
+
+	if err := c.CreateComment(context.Background(), "my-repo", 7, "hello"); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if _, ok := rec.body["threadContext"]; ok {
+		t.Error("general comment should not have a threadContext")
+	}
+	if rec.body["status"] != "active" {
+		t.Errorf("status = %v, want active", rec.body["status"])
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"personal access token", Config{}, "Basic OnNlY3JldA=="},
+		{"username", Config{Username: "user"}, "Basic dXNlcjpzZWNyZXQ="},
+		{"bearer", Config{Bearer: true}, "Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		tt.cfg.Endpoint, tt.cfg.Token, tt.cfg.Organization, tt.cfg.Project = srv.URL, "secret", "org", "proj"
+		c, err := NewClient(tt.cfg)
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		if err := c.CreateComment(context.Background(), "my-repo", 7, "hello"); err != nil {
+			t.Fatalf("CreateComment failed: %v", err)
+		}
+		if auth != tt.want {
+			t.Errorf("%s: Authorization = %q, want %q", tt.name, auth, tt.want)
+		}
+	}
+}
+
+func TestUpdateThreadStatus(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusOK, `{}`)
+	c := newTestClient(t, srv.URL)
+
+	if err := c.UpdateThreadStatus(context.Background(), "my-repo", 7, 42, "fixed"); err != nil {
+		t.Fatalf("UpdateThreadStatus failed: %v", err)
+	}
+	if rec.method != http.MethodPatch || rec.path != "/org/proj/_apis/git/repositories/my-repo/pullRequests/7/threads/42" {
+		t.Errorf("unexpected request %s %s", rec.method, rec.path)
+	}
+	if rec.body["status"] != "fixed" {
+		t.Errorf("status = %v, want fixed", rec.body["status"])
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusOK, `{}`)
+	c := newTestClient(t, srv.URL)
+
+	if err := c.UpdateDescription(context.Background(), "my-repo", 7, "new description"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+	if rec.method != http.MethodPatch || rec.path != "/org/proj/_apis/git/repositories/my-repo/pullRequests/7" {
+		t.Errorf("unexpected request %s %s", rec.method, rec.path)
+	}
+	if rec.body["description"] != "new description" {
+		t.Errorf("unexpected payload %v", rec.body)
+	}
+}
+
+func TestCreatePRStatus(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusCreated, `{}`)
+	c := newTestClient(t, srv.URL)
+
+	err := c.CreatePRStatus(context.Background(), "my-repo", 7, StatusInput{
+		State:       "success",
+		Context:     "ci/lint",
+		Description: "Linting passed",
+		TargetURL:   "https://ci.example.com/1",
+	})
+	if err != nil {
+		t.Fatalf("CreatePRStatus failed: %v", err)
+	}
+	if rec.path != "/org/proj/_apis/git/repositories/my-repo/pullRequests/7/statuses" {
+		t.Errorf("unexpected path %s", rec.path)
+	}
+	if rec.body["state"] != "succeeded" || rec.body["targetUrl"] != "https://ci.example.com/1" {
+		t.Errorf("unexpected payload %v", rec.body)
+	}
+	statusContext := rec.body["context"].(map[string]interface{})
+	if statusContext["genre"] != "ci" || statusContext["name"] != "lint" {
+		t.Errorf("unexpected context %v", statusContext)
+	}
+}
+
+func TestCreateThreadError(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, _ := newTestServer(t, http.StatusUnauthorized, `{"message": "denied"}`)
+	c := newTestClient(t, srv.URL)
+
+	if err := c.CreateComment(context.Background(), "my-repo", 7, "hello"); err == nil {
+		t.Error("CreateComment should fail on API error")
+	}
+}
+
+func TestMapState(t *testing.T) {
+	tests := map[string]string{
+		"success": "succeeded",
+		"failure": "failed",
+		"failed":  "failed",
+		"error":   "error",
+		"pending": "pending",
+		"running": "pending",
+		"unknown": "notSet",
+	}
+
+	for input, expected := range tests {
+		if got := mapState(input); got != expected {
+			t.Errorf("mapState(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestListThreads(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, rec := newTestServer(t, http.StatusOK, `{"count": 3, "value": [
+		{"id": 1, "status": "active", "comments": [{"content": "first"}, {"content": "reply"}]},
+		{"id": 2, "status": "fixed", "isDeleted": true, "comments": [{"content": "gone"}]},
+		{"id": 3, "comments": []}
+	]}`)
+	c := newTestClient(t, srv.URL)
+
+	threads, err := c.ListThreads(context.Background(), "my-repo", 7)
+	if err != nil {
+		t.Fatalf("ListThreads failed: %v", err)
+	}
+	if rec.method != http.MethodGet || rec.path != "/org/proj/_apis/git/repositories/my-repo/pullRequests/7/threads" {
+		t.Errorf("unexpected request %s %s", rec.method, rec.path)
+	}
+	if len(threads) != 2 || threads[0] != (Thread{ID: 1, Status: "active", Content: "first"}) || threads[1].ID != 3 {
+		t.Errorf("unexpected threads: %+v", threads)
+	}
+}
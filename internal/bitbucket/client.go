@@ -0,0 +1,229 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// maxAnnotations is the number of annotations Bitbucket accepts per request
+const maxAnnotations = 100
+
+// Client calls Bitbucket Cloud REST endpoints that go-scm does not cover. It
+// reuses the go-scm client's base URL and authenticated transport.
+type Client struct {
+	api *scmclient.REST
+	log *logrus.Entry
+}
+
+// InlineComment is a pull request comment on lines of a file. StartLine is
+// optional and anchors the comment to a range.
+type InlineComment struct {
+	Path      string
+	StartLine int
+	Line      int
+	Body      string
+}
+
+// Annotation is a Code Insights annotation on a line of a file
+type Annotation struct {
+	ExternalID string
+	Path       string
+	Line       int
+	Summary    string
+	Details    string
+	Severity   string // CRITICAL, HIGH, MEDIUM, LOW
+	Type       string // VULNERABILITY, CODE_SMELL, BUG
+	Link       string
+}
+
+// ReportInput describes a Code Insights report
+type ReportInput struct {
+	Title       string
+	Details     string
+	ReportType  string // SECURITY, COVERAGE, TEST, BUG
+	Reporter    string
+	Result      string // PASSED, FAILED, PENDING
+	Link        string
+	Annotations []Annotation
+}
+
+// NewClient creates a Bitbucket Cloud client on top of a go-scm client
+func NewClient(client *scm.Client) *Client {
+	log := logrus.WithField("component", "bitbucket")
+	return &Client{
+		api: scmclient.NewREST(client, "application/json", log),
+		log: log,
+	}
+}
+
+// CreateInlineComment comments on lines of a file in a pull request. The
+// comment is anchored to the new version of the file with inline.to, and
+// inline.start_to when it spans several lines.
+func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber int, comment InlineComment) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"file":      comment.Path,
+		"line":      comment.Line,
+	}).Info("creating inline comment")
+
+	inline := map[string]interface{}{
+		"path": comment.Path,
+		"to":   comment.Line,
+	}
+	if comment.StartLine > 0 && comment.StartLine < comment.Line {
+		inline["start_to"] = comment.StartLine
+	}
+
+	payload := map[string]interface{}{
+		"content": map[string]interface{}{"raw": comment.Body},
+		"inline":  inline,
+	}
+
+	path := fmt.Sprintf("2.0/repositories/%s/pullrequests/%d/comments", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to create inline comment: %w", err)
+	}
+
+	c.log.Info("created inline comment successfully")
+	return nil
+}
+
+// CreateCommitComment comments on a commit, anchored to a line of the new
+// version of a file when filePath and line are set
+func (c *Client) CreateCommitComment(ctx context.Context, repo, commitSHA, filePath string, line int, body string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":       repo,
+		"commit_sha": commitSHA,
+		"file":       filePath,
+		"line":       line,
+	}).Info("creating commit comment")
+
+	payload := map[string]interface{}{
+		"content": map[string]interface{}{"raw": body},
+	}
+	if filePath != "" && line > 0 {
+		payload["inline"] = map[string]interface{}{
+			"path": filePath,
+			"to":   line,
+		}
+	}
+
+	path := fmt.Sprintf("2.0/repositories/%s/commit/%s/comments", repo, commitSHA)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to create commit comment: %w", err)
+	}
+	return nil
+}
+
+// UpdateDescription replaces the description of a pull request. Bitbucket
+// treats an update as a replacement of the editable fields, so the title and
+// reviewers are read first and sent back unchanged.
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	path := fmt.Sprintf("2.0/repositories/%s/pullrequests/%d", repo, prNumber)
+
+	var pr struct {
+		Title     string `json:"title"`
+		Reviewers []struct {
+			UUID string `json:"uuid"`
+		} `json:"reviewers"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, &pr); err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	reviewers := make([]map[string]string, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		reviewers = append(reviewers, map[string]string{"uuid": reviewer.UUID})
+	}
+	payload := map[string]interface{}{
+		"title":       pr.Title,
+		"description": description,
+		"reviewers":   reviewers,
+	}
+	if err := c.api.Do(ctx, http.MethodPut, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// CreateReport creates or replaces a Code Insights report on a commit and
+// adds its annotations, at most 100 per request.
+func (c *Client) CreateReport(ctx context.Context, repo, commitSHA, reportID string, input ReportInput) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":        repo,
+		"commit":      commitSHA,
+		"report":      reportID,
+		"result":      input.Result,
+		"annotations": len(input.Annotations),
+	}).Info("creating Code Insights report")
+
+	reportPath := fmt.Sprintf("2.0/repositories/%s/commit/%s/reports/%s", repo, commitSHA, url.PathEscape(reportID))
+
+	payload := map[string]interface{}{
+		"title":       input.Title,
+		"details":     input.Details,
+		"report_type": input.ReportType,
+		"result":      input.Result,
+	}
+	if input.Reporter != "" {
+		payload["reporter"] = input.Reporter
+	}
+	if input.Link != "" {
+		payload["link"] = input.Link
+	}
+
+	if err := c.api.Do(ctx, http.MethodPut, reportPath, payload, nil); err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	for start := 0; start < len(input.Annotations); start += maxAnnotations {
+		end := min(start+maxAnnotations, len(input.Annotations))
+
+		items := make([]map[string]interface{}, 0, end-start)
+		for _, a := range input.Annotations[start:end] {
+			items = append(items, annotationPayload(a))
+		}
+
+		if err := c.api.Do(ctx, http.MethodPost, reportPath+"/annotations", items, nil); err != nil {
+			return fmt.Errorf("failed to add annotations %d-%d: %w", start+1, end, err)
+		}
+	}
+
+	c.log.WithField("report", reportID).Info("created Code Insights report successfully")
+	return nil
+}
+
+func annotationPayload(a Annotation) map[string]interface{} {
+	item := map[string]interface{}{
+		"external_id":     a.ExternalID,
+		"annotation_type": a.Type,
+		"summary":         a.Summary,
+		"severity":        a.Severity,
+	}
+	if a.Path != "" {
+		item["path"] = a.Path
+	}
+	if a.Line > 0 {
+		item["line"] = a.Line
+	}
+	if a.Details != "" {
+		item["details"] = a.Details
+	}
+	if a.Link != "" {
+		item["link"] = a.Link
+	}
+	return item
+}
@@ -0,0 +1,171 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-scm/scm/driver/bitbucket"
+	"github.com/sirupsen/logrus"
+)
+
+type request struct {
+	method string
+	path   string
+	body   interface{}
+}
+
+func newTestClient(t *testing.T, status int) (*Client, *[]request) {
+	t.Helper()
+
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.EscapedPath()}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	scmClient, err := bitbucket.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(scmClient), &requests
+}
+
+func TestCreateInlineComment(t *testing.T) {
+	tests := []struct {
+		name      string
+		comment   InlineComment
+		wantStart bool
+	}{
+		{"single line", InlineComment{Path: "a.go", StartLine: 4, Line: 4, Body: "fix"}, false},
+		{"range", InlineComment{Path: "a.go", StartLine: 2, Line: 4, Body: "fix"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requests := newTestClient(t, http.StatusCreated)
+
+			if err := c.CreateInlineComment(context.Background(), "workspace/repo", 5, tt.comment); err != nil {
+				t.Fatalf("CreateInlineComment failed: %v", err)
+			}
+
+			req := (*requests)[0]
+			if req.path != "/2.0/repositories/workspace/repo/pullrequests/5/comments" {
+				t.Errorf("unexpected path %s", req.path)
+			}
+			body := req.body.(map[string]interface{})
+			inline := body["inline"].(map[string]interface{})
+			if inline["path"] != "a.go" || inline["to"] != float64(4) {
+				t.Errorf("unexpected inline anchor %v", inline)
+			}
+			wantKeys := 2
+			if tt.wantStart {
+				wantKeys++
+				if inline["start_to"] != float64(2) {
+					t.Errorf("start_to = %v, want 2", inline["start_to"])
+				}
+			}
+			if len(inline) != wantKeys {
+				t.Errorf("unexpected inline fields %v", inline)
+			}
+		})
+	}
+}
+
+func TestCreateReport(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusOK)
+
+	var annotations []Annotation
+	for i := 1; i <= 150; i++ {
+		annotations = append(annotations, Annotation{ExternalID: fmt.Sprint(i), Path: "a.go", Line: i, Summary: "s", Severity: "LOW", Type: "CODE_SMELL"})
+	}
+
+	err := c.CreateReport(context.Background(), "workspace/repo", "abc", "comment-plugin", ReportInput{
+		Title:       "comment-plugin",
+		ReportType:  "BUG",
+		Result:      "FAILED",
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+
+	reqs := *requests
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+	reportPath := "/2.0/repositories/workspace/repo/commit/abc/reports/comment-plugin"
+	if reqs[0].method != http.MethodPut || reqs[0].path != reportPath {
+		t.Errorf("unexpected report request %s %s", reqs[0].method, reqs[0].path)
+	}
+	if reqs[0].body.(map[string]interface{})["result"] != "FAILED" {
+		t.Errorf("unexpected report payload %v", reqs[0].body)
+	}
+	for i, want := range []int{100, 50} {
+		req := reqs[i+1]
+		if req.method != http.MethodPost || req.path != reportPath+"/annotations" {
+			t.Errorf("unexpected annotations request %s %s", req.method, req.path)
+		}
+		if got := len(req.body.([]interface{})); got != want {
+			t.Errorf("batch %d has %d annotations, want %d", i+1, got, want)
+		}
+	}
+}
+
+func TestCreateReportError(t *testing.T) {
+	c, _ := newTestClient(t, http.StatusForbidden)
+
+	if err := c.CreateReport(context.Background(), "workspace/repo", "abc", "r", ReportInput{}); err == nil {
+		t.Fatal("CreateReport should fail on API error")
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusOK)
+
+	if err := c.UpdateDescription(context.Background(), "workspace/repo", 5, "new description"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+	get, put := (*requests)[0], (*requests)[1]
+	if get.method != http.MethodGet || put.method != http.MethodPut || put.path != "/2.0/repositories/workspace/repo/pullrequests/5" {
+		t.Errorf("unexpected requests %s %s, %s %s", get.method, get.path, put.method, put.path)
+	}
+	body := put.body.(map[string]interface{})
+	if body["description"] != "new description" {
+		t.Errorf("unexpected payload %v", body)
+	}
+	if _, ok := body["reviewers"]; !ok {
+		t.Error("reviewers should be sent back unchanged")
+	}
+}
+
+func TestCreateCommitComment(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusCreated)
+
+	if err := c.CreateCommitComment(context.Background(), "workspace/repo", "abc123", "a.go", 4, "fix"); err != nil {
+		t.Fatalf("CreateCommitComment failed: %v", err)
+	}
+
+	req := (*requests)[0]
+	if req.method != http.MethodPost || req.path != "/2.0/repositories/workspace/repo/commit/abc123/comments" {
+		t.Errorf("unexpected request %s %s", req.method, req.path)
+	}
+	inline := req.body.(map[string]interface{})["inline"].(map[string]interface{})
+	if inline["path"] != "a.go" || inline["to"] != float64(4) {
+		t.Errorf("unexpected inline anchor %v", inline)
+	}
+}
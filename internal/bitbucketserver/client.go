@@ -0,0 +1,202 @@
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// maxAnnotations is the number of annotations a report can hold
+const maxAnnotations = 1000
+
+// Client calls Bitbucket Server and Data Center REST endpoints that go-scm
+// does not cover. It reuses the go-scm client's base URL and authenticated
+// transport.
+type Client struct {
+	api *scmclient.REST
+	log *logrus.Entry
+}
+
+// Annotation is a Code Insights annotation on a line of a file
+type Annotation struct {
+	ExternalID string
+	Path       string
+	Line       int
+	Message    string
+	Severity   string // HIGH, MEDIUM, LOW
+	Type       string // VULNERABILITY, CODE_SMELL, BUG
+	Link       string
+}
+
+// ReportInput describes a Code Insights report
+type ReportInput struct {
+	Title       string
+	Details     string
+	Reporter    string
+	Result      string // PASS, FAIL
+	Link        string
+	Annotations []Annotation
+}
+
+// NewClient creates a Bitbucket Server client on top of a go-scm client
+func NewClient(client *scm.Client) *Client {
+	log := logrus.WithField("component", "bitbucket-server")
+	return &Client{
+		api: scmclient.NewREST(client, "application/json", log),
+		log: log,
+	}
+}
+
+// CreateReport creates or replaces a Code Insights report on a commit and
+// replaces its annotations. A report holds at most 1000 annotations; any
+// beyond that are dropped with a warning.
+func (c *Client) CreateReport(ctx context.Context, repo, commitSHA, key string, input ReportInput) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":        repo,
+		"commit":      commitSHA,
+		"report":      key,
+		"result":      input.Result,
+		"annotations": len(input.Annotations),
+	}).Info("creating Code Insights report")
+
+	reportPath, err := reportPath(repo, commitSHA, key)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"title":  input.Title,
+		"result": input.Result,
+	}
+	if input.Details != "" {
+		payload["details"] = input.Details
+	}
+	if input.Reporter != "" {
+		payload["reporter"] = input.Reporter
+	}
+	if input.Link != "" {
+		payload["link"] = input.Link
+	}
+
+	if err := c.api.Do(ctx, http.MethodPut, reportPath, payload, nil); err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	// Annotations from an earlier run of the same report would otherwise remain
+	if err := c.api.Do(ctx, http.MethodDelete, reportPath+"/annotations", nil, nil); err != nil {
+		return fmt.Errorf("failed to delete previous annotations: %w", err)
+	}
+
+	annotations := input.Annotations
+	if len(annotations) > maxAnnotations {
+		c.log.WithField("dropped", len(annotations)-maxAnnotations).Warn("report annotation limit reached")
+		annotations = annotations[:maxAnnotations]
+	}
+	if len(annotations) > 0 {
+		items := make([]map[string]interface{}, 0, len(annotations))
+		for _, a := range annotations {
+			items = append(items, annotationPayload(a))
+		}
+		body := map[string]interface{}{"annotations": items}
+		if err := c.api.Do(ctx, http.MethodPost, reportPath+"/annotations", body, nil); err != nil {
+			return fmt.Errorf("failed to add annotations: %w", err)
+		}
+	}
+
+	c.log.WithField("report", key).Info("created Code Insights report successfully")
+	return nil
+}
+
+func annotationPayload(a Annotation) map[string]interface{} {
+	item := map[string]interface{}{
+		"externalId": a.ExternalID,
+		"message":    a.Message,
+		"severity":   a.Severity,
+	}
+	if a.Path != "" {
+		item["path"] = a.Path
+	}
+	if a.Line > 0 {
+		item["line"] = a.Line
+	}
+	if a.Type != "" {
+		item["type"] = a.Type
+	}
+	if a.Link != "" {
+		item["link"] = a.Link
+	}
+	return item
+}
+
+// UpdateDescription replaces the description of a pull request. Bitbucket
+// Server requires the current version and replaces the title and reviewers
+// on update, so the pull request is read first and those are sent back
+// unchanged.
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	path, err := pullRequestPath(repo, prNumber)
+	if err != nil {
+		return err
+	}
+
+	var pr struct {
+		Version   int    `json:"version"`
+		Title     string `json:"title"`
+		Reviewers []struct {
+			User struct {
+				Name string `json:"name"`
+			} `json:"user"`
+		} `json:"reviewers"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, &pr); err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	reviewers := make([]map[string]interface{}, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		reviewers = append(reviewers, map[string]interface{}{
+			"user": map[string]string{"name": reviewer.User.Name},
+		})
+	}
+	payload := map[string]interface{}{
+		"version":     pr.Version,
+		"title":       pr.Title,
+		"description": description,
+		"reviewers":   reviewers,
+	}
+	if err := c.api.Do(ctx, http.MethodPut, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// pullRequestPath builds the path of a pull request from a "PROJECT/repo" name
+func pullRequestPath(repo string, prNumber int) (string, error) {
+	project, slug, ok := strings.Cut(repo, "/")
+	if !ok || project == "" || slug == "" {
+		return "", fmt.Errorf("invalid repo format, expected PROJECT/repo: %s", repo)
+	}
+	return fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s/pull-requests/%d",
+		url.PathEscape(project), url.PathEscape(slug), prNumber), nil
+}
+
+// reportPath builds the insights path of a report from a "PROJECT/repo" name
+func reportPath(repo, commitSHA, key string) (string, error) {
+	project, slug, ok := strings.Cut(repo, "/")
+	if !ok || project == "" || slug == "" {
+		return "", fmt.Errorf("invalid repo format, expected PROJECT/repo: %s", repo)
+	}
+	return fmt.Sprintf("rest/insights/1.0/projects/%s/repos/%s/commits/%s/reports/%s",
+		url.PathEscape(project), url.PathEscape(slug), commitSHA, url.PathEscape(key)), nil
+}
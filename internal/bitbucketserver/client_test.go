@@ -0,0 +1,136 @@
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-scm/scm/driver/stash"
+	"github.com/sirupsen/logrus"
+)
+
+type request struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+func newTestClient(t *testing.T, status int) (*Client, *[]request) {
+	t.Helper()
+
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.EscapedPath()}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	scmClient, err := stash.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(scmClient), &requests
+}
+
+func TestCreateReport(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusOK)
+
+	var annotations []Annotation
+	for i := 1; i <= 1005; i++ {
+		annotations = append(annotations, Annotation{ExternalID: fmt.Sprint(i), Path: "a.go", Line: i, Message: "m", Severity: "LOW"})
+	}
+
+	err := c.CreateReport(context.Background(), "PRJ/repo", "abc", "ai review", ReportInput{
+		Title:       "ai review",
+		Result:      "FAIL",
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+
+	reqs := *requests
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+	reportPath := "/rest/insights/1.0/projects/PRJ/repos/repo/commits/abc/reports/ai%20review"
+	want := []struct{ method, path string }{
+		{http.MethodPut, reportPath},
+		{http.MethodDelete, reportPath + "/annotations"},
+		{http.MethodPost, reportPath + "/annotations"},
+	}
+	for i, w := range want {
+		if reqs[i].method != w.method || reqs[i].path != w.path {
+			t.Errorf("request %d = %s %s, want %s %s", i, reqs[i].method, reqs[i].path, w.method, w.path)
+		}
+	}
+	if reqs[0].body["result"] != "FAIL" {
+		t.Errorf("unexpected report payload %v", reqs[0].body)
+	}
+	if got := len(reqs[2].body["annotations"].([]interface{})); got != maxAnnotations {
+		t.Errorf("sent %d annotations, want %d", got, maxAnnotations)
+	}
+}
+
+func TestCreateReportWithoutAnnotations(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusNoContent)
+
+	if err := c.CreateReport(context.Background(), "PRJ/repo", "abc", "r", ReportInput{Title: "r", Result: "PASS"}); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("got %d requests, want 2", len(*requests))
+	}
+}
+
+func TestCreateReportInvalidRepo(t *testing.T) {
+	c, requests := newTestClient(t, http.StatusOK)
+
+	if err := c.CreateReport(context.Background(), "repo", "abc", "r", ReportInput{}); err == nil {
+		t.Fatal("CreateReport should reject a repo without a project")
+	}
+	if len(*requests) != 0 {
+		t.Errorf("no request should be made, got %d", len(*requests))
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var update map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects/PRJ/repos/repo/pull-requests/7" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"version": 3, "title": "Add feature", "reviewers": [{"user": {"name": "jdoe"}}]}`))
+		case http.MethodPut:
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	scmClient, err := stash.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewClient(scmClient).UpdateDescription(context.Background(), "PRJ/repo", 7, "new description"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+	if update["version"] != float64(3) || update["title"] != "Add feature" || update["description"] != "new description" {
+		t.Errorf("unexpected update %v", update)
+	}
+	if reviewers, _ := update["reviewers"].([]interface{}); len(reviewers) != 1 {
+		t.Errorf("reviewers should be sent back unchanged, got %v", update["reviewers"])
+	}
+}
@@ -0,0 +1,299 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds configuration for the Gerrit client
+type Config struct {
+	Endpoint string
+	Username string
+	Token    string // HTTP password, or an OAuth token when Username is empty
+}
+
+// Client is a specialized client for Gerrit Code Review
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	baseURL    string
+	log        *logrus.Entry
+}
+
+// Range is a character range in a file. Lines are 1-based, characters are
+// 0-based and the end is exclusive.
+type Range struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+// Comment is a human-style file comment. Range is optional and takes
+// precedence over Line.
+type Comment struct {
+	Path       string
+	Line       int
+	Range      *Range
+	Message    string
+	Unresolved bool
+}
+
+// Replacement replaces a range of a file as part of a fix suggestion
+type Replacement struct {
+	Path        string `json:"path"`
+	Range       Range  `json:"range"`
+	Replacement string `json:"replacement"`
+}
+
+// FixSuggestion is a change Gerrit offers to apply from a robot comment
+type FixSuggestion struct {
+	Description  string        `json:"description"`
+	Replacements []Replacement `json:"replacements"`
+}
+
+// RobotComment is a file comment from an analyzer, optionally with fixes
+type RobotComment struct {
+	Comment
+	RobotID        string
+	RobotRunID     string
+	URL            string
+	Properties     map[string]string
+	FixSuggestions []FixSuggestion
+}
+
+// ReviewInput describes a review: a change message, label votes and file
+// comments posted in one request
+type ReviewInput struct {
+	Message       string
+	Tag           string
+	Labels        map[string]int
+	Comments      []Comment
+	RobotComments []RobotComment
+}
+
+// NewClient creates a new Gerrit client
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+
+	baseURL := strings.TrimSuffix(cfg.Endpoint, "/")
+
+	log := logrus.WithField("component", "gerrit")
+
+	log.WithFields(logrus.Fields{
+		"base_url": baseURL,
+		"username": cfg.Username,
+	}).Info("initialized Gerrit client")
+
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		log:        log,
+	}, nil
+}
+
+// SetReview posts a review on a revision of a change. The change is
+// identified by project and change number; revision is a commit SHA, a
+// patch set number or "current".
+func (c *Client) SetReview(ctx context.Context, project string, change int, revision string, input ReviewInput) error {
+	if revision == "" {
+		revision = "current"
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"project":        project,
+		"change":         change,
+		"revision":       revision,
+		"labels":         input.Labels,
+		"comments":       len(input.Comments),
+		"robot_comments": len(input.RobotComments),
+	}).Info("setting review")
+
+	payload := map[string]interface{}{}
+	if input.Message != "" {
+		payload["message"] = input.Message
+	}
+	if input.Tag != "" {
+		payload["tag"] = input.Tag
+	}
+	if len(input.Labels) > 0 {
+		payload["labels"] = input.Labels
+	}
+	if len(input.Comments) > 0 {
+		comments := make(map[string][]map[string]interface{})
+		for _, comment := range input.Comments {
+			comments[comment.Path] = append(comments[comment.Path], commentPayload(comment))
+		}
+		payload["comments"] = comments
+	}
+	if len(input.RobotComments) > 0 {
+		comments := make(map[string][]map[string]interface{})
+		for _, comment := range input.RobotComments {
+			comments[comment.Path] = append(comments[comment.Path], robotCommentPayload(comment))
+		}
+		payload["robot_comments"] = comments
+	}
+
+	path := c.apiPath(fmt.Sprintf("changes/%s/revisions/%s/review", changeID(project, change), url.PathEscape(revision)))
+
+	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to set review: %w", err)
+	}
+
+	c.log.WithField("change", change).Info("set review successfully")
+	return nil
+}
+
+// GetFileContent returns the content of a file in a revision of a change
+func (c *Client) GetFileContent(ctx context.Context, project string, change int, revision, file string) ([]byte, error) {
+	if revision == "" {
+		revision = "current"
+	}
+
+	path := c.apiPath(fmt.Sprintf("changes/%s/revisions/%s/files/%s/content",
+		changeID(project, change), url.PathEscape(revision), url.PathEscape(file)))
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	// The content is sent base64 encoded
+	encoded, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+}
+
+func commentPayload(comment Comment) map[string]interface{} {
+	item := map[string]interface{}{
+		"message":    comment.Message,
+		"unresolved": comment.Unresolved,
+	}
+	if comment.Range != nil {
+		item["range"] = comment.Range
+	} else if comment.Line > 0 {
+		item["line"] = comment.Line
+	}
+	return item
+}
+
+func robotCommentPayload(comment RobotComment) map[string]interface{} {
+	item := commentPayload(comment.Comment)
+	// Robot comments are always unresolved
+	delete(item, "unresolved")
+
+	item["robot_id"] = comment.RobotID
+	item["robot_run_id"] = comment.RobotRunID
+	if comment.URL != "" {
+		item["url"] = comment.URL
+	}
+	if len(comment.Properties) > 0 {
+		item["properties"] = comment.Properties
+	}
+	if len(comment.FixSuggestions) > 0 {
+		item["fix_suggestions"] = comment.FixSuggestions
+	}
+	return item
+}
+
+// changeID identifies a change as "project~number"; slashes in the project
+// name must be encoded
+func changeID(project string, change int) string {
+	if project == "" {
+		return fmt.Sprint(change)
+	}
+	return strings.ReplaceAll(url.PathEscape(project), "/", "%2F") + "~" + fmt.Sprint(change)
+}
+
+// apiPath builds the URL of a REST endpoint. Authenticated requests go
+// through the /a/ prefix.
+func (c *Client) apiPath(suffix string) string {
+	return fmt.Sprintf("%s/a/%s", c.baseURL, suffix)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(jsonBody)
+		c.log.WithField("payload", string(jsonBody)).Debug("request payload")
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"method": method,
+		"url":    path,
+	}).Debug("making API request")
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.log.WithError(err).Error("API request failed")
+		return nil, err
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"status_code": resp.StatusCode,
+		"status":      resp.Status,
+	}).Debug("received API response")
+
+	return resp, nil
+}
+
+func (c *Client) checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	c.log.WithFields(logrus.Fields{
+		"status_code":   resp.StatusCode,
+		"response_body": string(body),
+	}).Error("API request returned error")
+
+	return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+}
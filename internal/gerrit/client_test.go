@@ -0,0 +1,153 @@
+package gerrit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewClient(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	if _, err := NewClient(Config{Endpoint: "https://review.example.com", Token: "secret"}); err != nil {
+		t.Errorf("NewClient failed: %v", err)
+	}
+	if _, err := NewClient(Config{Endpoint: "https://review.example.com"}); err == nil {
+		t.Error("NewClient should fail without token")
+	}
+	if _, err := NewClient(Config{Token: "secret"}); err == nil {
+		t.Error("NewClient should fail without endpoint")
+	}
+}
+
+func TestChangeID(t *testing.T) {
+	tests := []struct {
+		project string
+		change  int
+		want    string
+	}{
+		{"", 42, "42"},
+		{"project", 42, "project~42"},
+		{"group/sub project", 7, "group%2Fsub%20project~7"},
+	}
+
+	for _, tt := range tests {
+		if got := changeID(tt.project, tt.change); got != tt.want {
+			t.Errorf("changeID(%q, %d) = %q, want %q", tt.project, tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestSetReview(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var path, user, password string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		user, password, _ = r.BasicAuth()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		// Gerrit prefixes JSON responses to prevent XSSI
+		_, _ = w.Write([]byte(")]}'\n{\"labels\": {}}"))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Config{Endpoint: srv.URL, Username: "bot", Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.SetReview(context.Background(), "platform/api", 1234, "", ReviewInput{
+		Message: "2 findings",
+		Tag:     "autogenerated:comment-plugin",
+		Labels:  map[string]int{"Code-Review": -1},
+		Comments: []Comment{
+			{Path: "main.go", Line: 3, Message: "inline", Unresolved: true},
+		},
+		RobotComments: []RobotComment{{
+			Comment:    Comment{Path: "main.go", Range: &Range{StartLine: 5, EndLine: 6}, Message: "robot"},
+			RobotID:    "comment-plugin",
+			RobotRunID: "abc",
+			FixSuggestions: []FixSuggestion{{
+				Description:  "Apply fix",
+				Replacements: []Replacement{{Path: "main.go", Range: Range{StartLine: 5, EndLine: 6}, Replacement: "fixed\n"}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("SetReview failed: %v", err)
+	}
+
+	if path != "/a/changes/platform%2Fapi~1234/revisions/current/review" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if user != "bot" || password != "secret" {
+		t.Errorf("unexpected credentials %q/%q", user, password)
+	}
+
+	labels := body["labels"].(map[string]interface{})
+	if labels["Code-Review"] != float64(-1) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	comments := body["comments"].(map[string]interface{})["main.go"].([]interface{})
+	if comment := comments[0].(map[string]interface{}); comment["line"] != float64(3) || comment["unresolved"] != true {
+		t.Errorf("unexpected comment %v", comment)
+	}
+
+	robots := body["robot_comments"].(map[string]interface{})["main.go"].([]interface{})
+	robot := robots[0].(map[string]interface{})
+	if robot["robot_id"] != "comment-plugin" || robot["range"] == nil || robot["line"] != nil {
+		t.Errorf("unexpected robot comment %v", robot)
+	}
+	if fixes := robot["fix_suggestions"].([]interface{}); len(fixes) != 1 {
+		t.Errorf("expected one fix suggestion, got %v", fixes)
+	}
+}
+
+func TestGetFileContent(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("package main\n"))))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "secret"})
+	content, err := c.GetFileContent(context.Background(), "platform/api", 7, "abc", "cmd/main.go")
+	if err != nil {
+		t.Fatalf("GetFileContent failed: %v", err)
+	}
+	if string(content) != "package main\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if path != "/a/changes/platform%2Fapi~7/revisions/abc/files/cmd%2Fmain.go/content" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
+
+func TestSetReviewError(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("change is closed"))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "secret"})
+	if err := c.SetReview(context.Background(), "project", 1, "abc", ReviewInput{Message: "hi"}); err == nil {
+		t.Fatal("SetReview should fail on API error")
+	}
+}
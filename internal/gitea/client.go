@@ -0,0 +1,236 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// Client calls Gitea (and Forgejo) REST endpoints that go-scm does not cover.
+// It reuses the go-scm client's base URL and authenticated transport.
+type Client struct {
+	api *scmclient.REST
+	log *logrus.Entry
+}
+
+// labelsPerPage is the page size used when listing repository labels
+const labelsPerPage = 50
+
+// ReviewComment is an inline comment on a line of the new file
+type ReviewComment struct {
+	Path string
+	Line int
+	Body string
+}
+
+// ReviewInput describes a pull request review
+type ReviewInput struct {
+	CommitSHA string
+	Body      string
+	Event     string // COMMENT, APPROVED, REQUEST_CHANGES
+	Comments  []ReviewComment
+}
+
+// NewClient creates a Gitea client on top of a go-scm client
+func NewClient(client *scm.Client) *Client {
+	log := logrus.WithField("component", "gitea")
+	return &Client{
+		api: scmclient.NewREST(client, "application/json", log),
+		log: log,
+	}
+}
+
+// CreateReview submits a pull review with all of its comments in one request
+func (c *Client) CreateReview(ctx context.Context, repo string, prNumber int, input ReviewInput) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"event":     input.Event,
+		"comments":  len(input.Comments),
+	}).Info("creating PR review")
+
+	event := input.Event
+	if event == "" {
+		event = "COMMENT"
+	}
+
+	comments := make([]map[string]interface{}, 0, len(input.Comments))
+	for _, comment := range input.Comments {
+		comments = append(comments, map[string]interface{}{
+			"path":         comment.Path,
+			"body":         comment.Body,
+			"new_position": comment.Line,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"body":     input.Body,
+		"event":    event,
+		"comments": comments,
+	}
+	if input.CommitSHA != "" {
+		payload["commit_id"] = input.CommitSHA
+	}
+
+	path := fmt.Sprintf("api/v1/repos/%s/pulls/%d/reviews", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		if scmclient.Unsupported(err) {
+			return fmt.Errorf("%w: %v", scm.ErrNotSupported, err)
+		}
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	c.log.WithField("comments", len(input.Comments)).Info("created PR review successfully")
+	return nil
+}
+
+// SubmitReview submits a review without inline comments, for a review state
+// (COMMENT, APPROVED or REQUEST_CHANGES) and body on their own
+func (c *Client) SubmitReview(ctx context.Context, repo string, prNumber int, commitSHA, event, body string) error {
+	return c.CreateReview(ctx, repo, prNumber, ReviewInput{CommitSHA: commitSHA, Body: body, Event: event})
+}
+
+// AddLabels adds existing repository labels to a pull request. Gitea only
+// accepts label IDs, so names are looked up first; unknown labels are logged
+// and skipped.
+func (c *Client) AddLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("adding PR labels")
+
+	ids, err := c.labelIDs(ctx, repo, labels)
+	if err != nil {
+		return err
+	}
+
+	values := make([]int64, 0, len(ids))
+	for _, label := range labels {
+		id, ok := ids[label]
+		if !ok {
+			c.log.WithField("label", label).Warn("label does not exist in the repository, skipping")
+			continue
+		}
+		values = append(values, id)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf("api/v1/repos/%s/issues/%d/labels", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, map[string]interface{}{"labels": values}, nil); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
+}
+
+// RemoveLabels removes labels from a pull request. Labels that do not exist
+// or are not on the pull request are ignored.
+func (c *Client) RemoveLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("removing PR labels")
+
+	ids, err := c.labelIDs(ctx, repo, labels)
+	if err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		id, ok := ids[label]
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("api/v1/repos/%s/issues/%d/labels/%d", repo, prNumber, id)
+		err := c.api.Do(ctx, http.MethodDelete, path, nil, nil)
+		if scmclient.HasStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove label %q: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews from CODEOWNERS owners: @user for a
+// user and @org/team for a team of the organization. Email owners cannot be
+// requested and are skipped.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, prNumber int, owners []string) error {
+	split := scmclient.SplitOwners(owners)
+	for _, email := range split.Emails {
+		c.log.WithField("owner", email).Warn("email owners cannot be requested as reviewers, skipping")
+	}
+	users, teams := split.Users, split.Teams
+	if len(users) == 0 && len(teams) == 0 {
+		return nil
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"users":     users,
+		"teams":     teams,
+	}).Info("requesting PR reviewers")
+
+	payload := map[string]interface{}{
+		"reviewers":      users,
+		"team_reviewers": teams,
+	}
+	path := fmt.Sprintf("api/v1/repos/%s/pulls/%d/requested_reviewers", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers: %w", err)
+	}
+	return nil
+}
+
+// UpdateDescription replaces the body of a pull request
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	path := fmt.Sprintf("api/v1/repos/%s/pulls/%d", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPatch, path, map[string]interface{}{"body": description}, nil); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// labelIDs maps the given label names to their repository label IDs
+func (c *Client) labelIDs(ctx context.Context, repo string, names []string) (map[string]int64, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	ids := make(map[string]int64, len(names))
+	for page := 1; ; page++ {
+		var labels []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("api/v1/repos/%s/labels?page=%d&limit=%d", repo, page, labelsPerPage)
+		if err := c.api.Do(ctx, http.MethodGet, path, nil, &labels); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, label := range labels {
+			if wanted[label.Name] {
+				ids[label.Name] = label.ID
+			}
+		}
+		if len(labels) < labelsPerPage {
+			break
+		}
+	}
+	return ids, nil
+}
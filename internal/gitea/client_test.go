@@ -0,0 +1,167 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/gitea"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	scmClient, err := gitea.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(scmClient)
+}
+
+func TestCreateReview(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+
+	err := c.CreateReview(context.Background(), "owner/repo", 3, ReviewInput{
+		Body:     "summary",
+		Comments: []ReviewComment{{Path: "a.go", Line: 5, Body: "fix"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	if path != "/api/v1/repos/owner/repo/pulls/3/reviews" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if body["event"] != "COMMENT" || body["body"] != "summary" {
+		t.Errorf("unexpected payload %v", body)
+	}
+	if _, ok := body["commit_id"]; ok {
+		t.Errorf("commit_id should be omitted when unset: %v", body)
+	}
+	comments, _ := body["comments"].([]interface{})
+	if len(comments) != 1 {
+		t.Fatalf("got %d comments, want 1", len(comments))
+	}
+	comment := comments[0].(map[string]interface{})
+	if comment["path"] != "a.go" || comment["new_position"] != float64(5) {
+		t.Errorf("unexpected comment %v", comment)
+	}
+}
+
+func TestCreateReviewErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		unsupported bool
+	}{
+		{"not found", http.StatusNotFound, true},
+		{"not implemented", http.StatusNotImplemented, true},
+		{"unprocessable", http.StatusUnprocessableEntity, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			err := c.CreateReview(context.Background(), "owner/repo", 3, ReviewInput{})
+			if err == nil {
+				t.Fatal("CreateReview should fail on API error")
+			}
+			if got := errors.Is(err, scm.ErrNotSupported); got != tt.unsupported {
+				t.Errorf("errors.Is(err, ErrNotSupported) = %v, want %v", got, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	var added []interface{}
+	var removed []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/repos/owner/repo/labels":
+			_, _ = w.Write([]byte(`[{"id": 1, "name": "ai-reviewed"}, {"id": 2, "name": "needs-attention"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/repos/owner/repo/issues/5/labels":
+			var body map[string][]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			added = body["labels"]
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodDelete:
+			removed = append(removed, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	if err := c.AddLabels(context.Background(), "owner/repo", 5, []string{"ai-reviewed", "missing"}); err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	if len(added) != 1 || added[0] != float64(1) {
+		t.Errorf("added label IDs = %v, want [1]", added)
+	}
+
+	if err := c.RemoveLabels(context.Background(), "owner/repo", 5, []string{"needs-attention", "missing"}); err != nil {
+		t.Fatalf("RemoveLabels failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "/api/v1/repos/owner/repo/issues/5/labels/2" {
+		t.Errorf("removed = %v", removed)
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	var body map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/v1/repos/owner/repo/pulls/5" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.UpdateDescription(context.Background(), "owner/repo", 5, "new body"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+	if body["body"] != "new body" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestRequestReviewers(t *testing.T) {
+	var body map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/owner/repo/pulls/5/requested_reviewers" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	if err := c.RequestReviewers(context.Background(), "owner/repo", 5, []string{"@alice", "@org/reviewers"}); err != nil {
+		t.Fatalf("RequestReviewers failed: %v", err)
+	}
+	users, _ := body["reviewers"].([]interface{})
+	teams, _ := body["team_reviewers"].([]interface{})
+	if len(users) != 1 || users[0] != "alice" || len(teams) != 1 || teams[0] != "reviewers" {
+		t.Errorf("body = %v", body)
+	}
+}
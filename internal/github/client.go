@@ -0,0 +1,349 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// maxAnnotations is the number of annotations GitHub accepts per request
+const maxAnnotations = 50
+
+// Client calls GitHub REST endpoints that go-scm does not cover. It reuses the
+// go-scm client's base URL and authenticated transport.
+type Client struct {
+	api *scmclient.REST
+	log *logrus.Entry
+}
+
+// Annotation is a check run annotation. Columns are only sent when the
+// annotation covers a single line, as GitHub requires.
+type Annotation struct {
+	Path        string
+	StartLine   int
+	EndLine     int
+	StartColumn int
+	EndColumn   int
+	Level       string // notice, warning, failure
+	Title       string
+	Message     string
+}
+
+// CheckRunInput describes a check run
+type CheckRunInput struct {
+	Name        string
+	HeadSHA     string
+	Status      string // queued, in_progress, completed
+	Conclusion  string // success, failure, neutral, ... (completed only)
+	DetailsURL  string
+	Title       string
+	Summary     string
+	Annotations []Annotation
+}
+
+// ReviewComment is an inline comment submitted as part of a review. StartLine
+// is optional and turns the comment into a multi-line comment.
+type ReviewComment struct {
+	Path      string
+	StartLine int
+	Line      int
+	Body      string
+}
+
+// ReviewInput describes a pull request review
+type ReviewInput struct {
+	CommitSHA string
+	Body      string
+	Event     string // COMMENT, APPROVE, REQUEST_CHANGES
+	Comments  []ReviewComment
+}
+
+// NewClient creates a GitHub client on top of a go-scm client
+func NewClient(client *scm.Client) *Client {
+	log := logrus.WithField("component", "github")
+	return &Client{
+		api: scmclient.NewREST(client, "application/vnd.github+json", log),
+		log: log,
+	}
+}
+
+// CreateCheckRun creates a check run and attaches its annotations. GitHub
+// accepts at most 50 annotations per request, so the remainder are added by
+// updating the check run in batches.
+func (c *Client) CreateCheckRun(ctx context.Context, repo string, input CheckRunInput) (int64, error) {
+	c.log.WithFields(logrus.Fields{
+		"repo":        repo,
+		"name":        input.Name,
+		"head_sha":    input.HeadSHA,
+		"status":      input.Status,
+		"conclusion":  input.Conclusion,
+		"annotations": len(input.Annotations),
+	}).Info("creating check run")
+
+	batches := batchAnnotations(input.Annotations)
+
+	payload := map[string]interface{}{
+		"name":     input.Name,
+		"head_sha": input.HeadSHA,
+		"status":   input.Status,
+		"output":   checkOutput(input, batches[0]),
+	}
+	if input.Status == "completed" {
+		payload["conclusion"] = input.Conclusion
+	}
+	if input.DetailsURL != "" {
+		payload["details_url"] = input.DetailsURL
+	}
+
+	var run struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.api.Do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/check-runs", repo), payload, &run); err != nil {
+		return 0, fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for i, batch := range batches[1:] {
+		path := fmt.Sprintf("repos/%s/check-runs/%d", repo, run.ID)
+		update := map[string]interface{}{"output": checkOutput(input, batch)}
+		if err := c.api.Do(ctx, http.MethodPatch, path, update, nil); err != nil {
+			return run.ID, fmt.Errorf("failed to add annotations batch %d: %w", i+2, err)
+		}
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"check_run_id": run.ID,
+		"batches":      len(batches),
+	}).Info("created check run successfully")
+	return run.ID, nil
+}
+
+func checkOutput(input CheckRunInput, annotations []Annotation) map[string]interface{} {
+	title := input.Title
+	if title == "" {
+		title = input.Name
+	}
+
+	output := map[string]interface{}{
+		"title":   title,
+		"summary": input.Summary,
+	}
+	if len(annotations) > 0 {
+		items := make([]map[string]interface{}, 0, len(annotations))
+		for _, a := range annotations {
+			items = append(items, annotationPayload(a))
+		}
+		output["annotations"] = items
+	}
+	return output
+}
+
+func annotationPayload(a Annotation) map[string]interface{} {
+	endLine := a.EndLine
+	if endLine < a.StartLine {
+		endLine = a.StartLine
+	}
+
+	item := map[string]interface{}{
+		"path":             a.Path,
+		"start_line":       a.StartLine,
+		"end_line":         endLine,
+		"annotation_level": a.Level,
+		"message":          a.Message,
+	}
+	if a.Title != "" {
+		item["title"] = a.Title
+	}
+	if a.StartLine == endLine && a.StartColumn > 0 && a.EndColumn >= a.StartColumn {
+		item["start_column"] = a.StartColumn
+		item["end_column"] = a.EndColumn
+	}
+	return item
+}
+
+// batchAnnotations splits annotations into request-sized batches. There is
+// always at least one (possibly empty) batch.
+func batchAnnotations(annotations []Annotation) [][]Annotation {
+	batches := [][]Annotation{nil}
+	for start := 0; start < len(annotations); start += maxAnnotations {
+		end := start + maxAnnotations
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		if start == 0 {
+			batches[0] = annotations[start:end]
+		} else {
+			batches = append(batches, annotations[start:end])
+		}
+	}
+	return batches
+}
+
+// CreateReview submits a review with all of its inline comments in a single
+// request, so reviewers get one notification instead of one per comment.
+func (c *Client) CreateReview(ctx context.Context, repo string, prNumber int, input ReviewInput) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"event":     input.Event,
+		"comments":  len(input.Comments),
+	}).Info("creating PR review")
+
+	event := input.Event
+	if event == "" {
+		event = "COMMENT"
+	}
+
+	comments := make([]map[string]interface{}, 0, len(input.Comments))
+	for _, comment := range input.Comments {
+		item := map[string]interface{}{
+			"path": comment.Path,
+			"body": comment.Body,
+			"line": comment.Line,
+			"side": "RIGHT",
+		}
+		if comment.StartLine > 0 && comment.StartLine < comment.Line {
+			item["start_line"] = comment.StartLine
+			item["start_side"] = "RIGHT"
+		}
+		comments = append(comments, item)
+	}
+
+	payload := map[string]interface{}{
+		"body":     input.Body,
+		"event":    event,
+		"comments": comments,
+	}
+	if input.CommitSHA != "" {
+		payload["commit_id"] = input.CommitSHA
+	}
+
+	path := fmt.Sprintf("repos/%s/pulls/%d/reviews", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		if scmclient.Unsupported(err) {
+			return fmt.Errorf("%w: %v", scm.ErrNotSupported, err)
+		}
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	c.log.WithField("comments", len(input.Comments)).Info("created PR review successfully")
+	return nil
+}
+
+// SubmitReview submits a review without inline comments, for a review state
+// (COMMENT, APPROVE or REQUEST_CHANGES) and body on their own
+func (c *Client) SubmitReview(ctx context.Context, repo string, prNumber int, commitSHA, event, body string) error {
+	return c.CreateReview(ctx, repo, prNumber, ReviewInput{CommitSHA: commitSHA, Body: body, Event: event})
+}
+
+// CreateCommitComment comments on a commit, anchored to a line of a file
+// when filePath and line are set
+func (c *Client) CreateCommitComment(ctx context.Context, repo, commitSHA, filePath string, line int, body string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":       repo,
+		"commit_sha": commitSHA,
+		"file":       filePath,
+		"line":       line,
+	}).Info("creating commit comment")
+
+	payload := map[string]interface{}{"body": body}
+	if filePath != "" && line > 0 {
+		payload["path"] = filePath
+		payload["line"] = line
+	}
+
+	path := fmt.Sprintf("repos/%s/commits/%s/comments", repo, commitSHA)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to create commit comment: %w", err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a pull request, creating any that do not exist
+// in the repository yet
+func (c *Client) AddLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("adding PR labels")
+
+	path := fmt.Sprintf("repos/%s/issues/%d/labels", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, map[string]interface{}{"labels": labels}, nil); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
+}
+
+// RemoveLabels removes labels from a pull request. Labels that are not on
+// the pull request are ignored.
+func (c *Client) RemoveLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("removing PR labels")
+
+	for _, label := range labels {
+		path := fmt.Sprintf("repos/%s/issues/%d/labels/%s", repo, prNumber, url.PathEscape(label))
+		err := c.api.Do(ctx, http.MethodDelete, path, nil, nil)
+		if scmclient.HasStatus(err, http.StatusNotFound) {
+			c.log.WithField("label", label).Debug("label not on pull request")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove label %q: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews from CODEOWNERS owners: @user for a
+// user and @org/team for a team of the organization. Email owners cannot be
+// requested and are skipped.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, prNumber int, owners []string) error {
+	split := scmclient.SplitOwners(owners)
+	for _, email := range split.Emails {
+		c.log.WithField("owner", email).Warn("email owners cannot be requested as reviewers, skipping")
+	}
+	users, teams := split.Users, split.Teams
+	if len(users) == 0 && len(teams) == 0 {
+		return nil
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"users":     users,
+		"teams":     teams,
+	}).Info("requesting PR reviewers")
+
+	payload := map[string]interface{}{
+		"reviewers":      users,
+		"team_reviewers": teams,
+	}
+	path := fmt.Sprintf("repos/%s/pulls/%d/requested_reviewers", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers: %w", err)
+	}
+	return nil
+}
+
+// UpdateDescription replaces the body of a pull request
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	path := fmt.Sprintf("repos/%s/pulls/%d", repo, prNumber)
+	if err := c.api.Do(ctx, http.MethodPatch, path, map[string]interface{}{"body": description}, nil); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
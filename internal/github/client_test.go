@@ -0,0 +1,284 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/github"
+	"github.com/sirupsen/logrus"
+)
+
+type request struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+func newTestClient(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*Client, *[]request) {
+	t.Helper()
+
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.Path}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	scmClient, err := github.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(scmClient), &requests
+}
+
+func annotationsIn(req request) int {
+	output, _ := req.body["output"].(map[string]interface{})
+	annotations, _ := output["annotations"].([]interface{})
+	return len(annotations)
+}
+
+func TestCreateCheckRunBatchesAnnotations(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 99}`))
+	})
+
+	var annotations []Annotation
+	for i := 1; i <= 120; i++ {
+		annotations = append(annotations, Annotation{Path: "main.go", StartLine: i, EndLine: i, Level: "warning", Message: fmt.Sprint(i)})
+	}
+
+	id, err := c.CreateCheckRun(context.Background(), "owner/repo", CheckRunInput{
+		Name:        "review",
+		HeadSHA:     "abc",
+		Status:      "completed",
+		Conclusion:  "neutral",
+		Summary:     "summary",
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckRun failed: %v", err)
+	}
+	if id != 99 {
+		t.Errorf("check run id = %d, want 99", id)
+	}
+
+	reqs := *requests
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+	if reqs[0].method != http.MethodPost || reqs[0].path != "/repos/owner/repo/check-runs" {
+		t.Errorf("unexpected first request %s %s", reqs[0].method, reqs[0].path)
+	}
+	if reqs[0].body["conclusion"] != "neutral" || reqs[0].body["head_sha"] != "abc" {
+		t.Errorf("unexpected create payload %v", reqs[0].body)
+	}
+	for i, want := range []int{50, 50, 20} {
+		if got := annotationsIn(reqs[i]); got != want {
+			t.Errorf("request %d has %d annotations, want %d", i, got, want)
+		}
+	}
+	for _, req := range reqs[1:] {
+		if req.method != http.MethodPatch || req.path != "/repos/owner/repo/check-runs/99" {
+			t.Errorf("unexpected update request %s %s", req.method, req.path)
+		}
+	}
+}
+
+func TestCreateCheckRunInProgressOmitsConclusion(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+
+	if _, err := c.CreateCheckRun(context.Background(), "owner/repo", CheckRunInput{Name: "review", HeadSHA: "abc", Status: "in_progress"}); err != nil {
+		t.Fatalf("CreateCheckRun failed: %v", err)
+	}
+	if _, ok := (*requests)[0].body["conclusion"]; ok {
+		t.Error("in-progress check run should not have a conclusion")
+	}
+}
+
+func TestCreateCheckRunError(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	})
+
+	_, err := c.CreateCheckRun(context.Background(), "owner/repo", CheckRunInput{Name: "review", HeadSHA: "abc", Status: "queued"})
+	if err == nil {
+		t.Fatal("CreateCheckRun should fail on API error")
+	}
+}
+
+func TestAnnotationPayloadColumns(t *testing.T) {
+	single := annotationPayload(Annotation{Path: "a.go", StartLine: 3, EndLine: 3, StartColumn: 2, EndColumn: 9})
+	if single["start_column"] != 2 || single["end_column"] != 9 {
+		t.Errorf("single-line annotation should keep columns: %v", single)
+	}
+
+	multi := annotationPayload(Annotation{Path: "a.go", StartLine: 3, EndLine: 5, StartColumn: 2, EndColumn: 9})
+	if _, ok := multi["start_column"]; ok {
+		t.Errorf("multi-line annotation must not send columns: %v", multi)
+	}
+}
+
+func TestCreateReview(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+
+	err := c.CreateReview(context.Background(), "owner/repo", 7, ReviewInput{
+		CommitSHA: "abc",
+		Body:      "summary",
+		Comments: []ReviewComment{
+			{Path: "a.go", Line: 4, Body: "single"},
+			{Path: "b.go", StartLine: 2, Line: 6, Body: "range"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	reqs := *requests
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	req := reqs[0]
+	if req.method != http.MethodPost || req.path != "/repos/owner/repo/pulls/7/reviews" {
+		t.Errorf("unexpected request %s %s", req.method, req.path)
+	}
+	if req.body["event"] != "COMMENT" || req.body["commit_id"] != "abc" || req.body["body"] != "summary" {
+		t.Errorf("unexpected review payload %v", req.body)
+	}
+
+	comments, _ := req.body["comments"].([]interface{})
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+	single := comments[0].(map[string]interface{})
+	if _, ok := single["start_line"]; ok {
+		t.Errorf("single-line comment must not send start_line: %v", single)
+	}
+	multi := comments[1].(map[string]interface{})
+	if multi["start_line"] != float64(2) || multi["line"] != float64(6) {
+		t.Errorf("unexpected multi-line comment %v", multi)
+	}
+}
+
+func TestCreateReviewNotSupported(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	err := c.CreateReview(context.Background(), "owner/repo", 7, ReviewInput{})
+	if !errors.Is(err, scm.ErrNotSupported) {
+		t.Errorf("expected scm.ErrNotSupported, got %v", err)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/issues/5/labels/needs attention" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	if err := c.AddLabels(context.Background(), "owner/repo", 5, []string{"ai-reviewed"}); err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	if err := c.RemoveLabels(context.Background(), "owner/repo", 5, []string{"needs attention", "stale"}); err != nil {
+		t.Fatalf("RemoveLabels should ignore labels not on the pull request: %v", err)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(*requests))
+	}
+	add := (*requests)[0]
+	if add.method != http.MethodPost || add.path != "/repos/owner/repo/issues/5/labels" {
+		t.Errorf("unexpected add request %s %s", add.method, add.path)
+	}
+	if labels, _ := add.body["labels"].([]interface{}); len(labels) != 1 || labels[0] != "ai-reviewed" {
+		t.Errorf("unexpected labels %v", add.body)
+	}
+	if remove := (*requests)[2]; remove.method != http.MethodDelete || remove.path != "/repos/owner/repo/issues/5/labels/stale" {
+		t.Errorf("unexpected remove request %s %s", remove.method, remove.path)
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.UpdateDescription(context.Background(), "owner/repo", 5, "new body"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	if req.method != http.MethodPatch || req.path != "/repos/owner/repo/pulls/5" || req.body["body"] != "new body" {
+		t.Errorf("unexpected request %s %s %v", req.method, req.path, req.body)
+	}
+}
+
+func TestRequestReviewers(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	owners := []string{"@octocat", "@acme/backend", "dev@example.com"}
+	if err := c.RequestReviewers(context.Background(), "owner/repo", 5, owners); err != nil {
+		t.Fatalf("RequestReviewers failed: %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	if req.method != http.MethodPost || req.path != "/repos/owner/repo/pulls/5/requested_reviewers" {
+		t.Errorf("unexpected request %s %s", req.method, req.path)
+	}
+	if got := fmt.Sprint(req.body["reviewers"], req.body["team_reviewers"]); got != "[octocat] [backend]" {
+		t.Errorf("reviewers, teams = %s", got)
+	}
+}
+
+func TestCreateCommitComment(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.CreateCommitComment(context.Background(), "owner/repo", "abc123", "main.go", 12, "fix"); err != nil {
+		t.Fatalf("CreateCommitComment failed: %v", err)
+	}
+	if err := c.CreateCommitComment(context.Background(), "owner/repo", "abc123", "", 0, "summary"); err != nil {
+		t.Fatalf("CreateCommitComment failed: %v", err)
+	}
+
+	inline, general := (*requests)[0], (*requests)[1]
+	if inline.method != http.MethodPost || inline.path != "/repos/owner/repo/commits/abc123/comments" {
+		t.Errorf("unexpected request %s %s", inline.method, inline.path)
+	}
+	if inline.body["path"] != "main.go" || inline.body["line"] != float64(12) || inline.body["body"] != "fix" {
+		t.Errorf("unexpected inline payload %v", inline.body)
+	}
+	if _, ok := general.body["path"]; ok {
+		t.Errorf("general comment should not have a path: %v", general.body)
+	}
+}
@@ -0,0 +1,438 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// diffsPerPage is the page size used when listing merge request diffs
+const diffsPerPage = 100
+
+// Client calls GitLab REST endpoints that go-scm does not cover. It reuses
+// the go-scm client's base URL and authenticated transport.
+type Client struct {
+	api *scmclient.REST
+	log *logrus.Entry
+}
+
+// ErrNotPublished is returned by CreateReview when the draft notes could not
+// be published. The drafts are deleted, so the comments can be posted again
+// as discussions.
+var ErrNotPublished = errors.New("draft notes could not be published")
+
+// DiffRefs are the SHAs a merge request diff is computed from
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+// ReviewComment is an inline comment on a line of the new file
+type ReviewComment struct {
+	Path string
+	Line int
+	Body string
+}
+
+// ReviewInput describes a batch of merge request comments
+type ReviewInput struct {
+	Body     string
+	Comments []ReviewComment
+}
+
+// NewClient creates a GitLab client on top of a go-scm client
+func NewClient(client *scm.Client) *Client {
+	log := logrus.WithField("component", "gitlab")
+	return &Client{
+		api: scmclient.NewREST(client, "application/json", log),
+		log: log,
+	}
+}
+
+// GetDiffRefs fetches the diff refs of a merge request
+func (c *Client) GetDiffRefs(ctx context.Context, repo string, mrNumber int) (*DiffRefs, error) {
+	var mr struct {
+		DiffRefs *DiffRefs `json:"diff_refs"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, mergeRequestPath(repo, mrNumber, ""), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request: %w", err)
+	}
+	if mr.DiffRefs == nil {
+		return nil, fmt.Errorf("merge request %d has no diff refs yet", mrNumber)
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"base_sha":  mr.DiffRefs.BaseSHA,
+		"start_sha": mr.DiffRefs.StartSHA,
+		"head_sha":  mr.DiffRefs.HeadSHA,
+	}).Debug("fetched merge request diff refs")
+
+	return mr.DiffRefs, nil
+}
+
+// GetDiffs fetches the per-file diffs of a merge request, falling back to
+// the older changes endpoint on GitLab versions without the diffs API
+func (c *Client) GetDiffs(ctx context.Context, repo string, mrNumber int) ([]FileDiff, error) {
+	var diffs []FileDiff
+	for page := 1; ; page++ {
+		var batch []FileDiff
+		path := mergeRequestPath(repo, mrNumber, fmt.Sprintf("diffs?page=%d&per_page=%d", page, diffsPerPage))
+		err := c.api.Do(ctx, http.MethodGet, path, nil, &batch)
+		if err != nil && page == 1 && scmclient.Unsupported(err) {
+			return c.getChanges(ctx, repo, mrNumber)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merge request diffs: %w", err)
+		}
+
+		diffs = append(diffs, batch...)
+		if len(batch) < diffsPerPage {
+			break
+		}
+	}
+
+	c.log.WithField("files", len(diffs)).Debug("fetched merge request diffs")
+	return diffs, nil
+}
+
+func (c *Client) getChanges(ctx context.Context, repo string, mrNumber int) ([]FileDiff, error) {
+	var mr struct {
+		Changes []FileDiff `json:"changes"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, mergeRequestPath(repo, mrNumber, "changes"), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request changes: %w", err)
+	}
+	return mr.Changes, nil
+}
+
+// getMergeRequestDiff fetches the diff refs and file diffs used to position
+// notes
+func (c *Client) getMergeRequestDiff(ctx context.Context, repo string, mrNumber int) (*mergeRequestDiff, error) {
+	refs, err := c.GetDiffRefs(ctx, repo, mrNumber)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := c.GetDiffs(ctx, repo, mrNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*FileDiff, len(diffs))
+	for i := range diffs {
+		files[diffs[i].NewPath] = &diffs[i]
+	}
+	return &mergeRequestDiff{refs: refs, files: files}, nil
+}
+
+// CreateDiscussion starts a discussion on a line of the merge request diff
+func (c *Client) CreateDiscussion(ctx context.Context, repo string, mrNumber int, comment ReviewComment) error {
+	_, err := c.CreateDiscussions(ctx, repo, mrNumber, []ReviewComment{comment})
+	return err
+}
+
+// CreateDiscussions starts one discussion per comment, positioned on the
+// merge request diff. Comments that GitLab rejects on their line are retried
+// as general notes, and those rejected again are logged and skipped; the
+// number created is returned. An error is returned when nothing could be
+// created.
+func (c *Client) CreateDiscussions(ctx context.Context, repo string, mrNumber int, comments []ReviewComment) (int, error) {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"mr_number": mrNumber,
+		"comments":  len(comments),
+	}).Info("creating merge request discussions")
+
+	diff, err := c.getMergeRequestDiff(ctx, repo, mrNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	path := mergeRequestPath(repo, mrNumber, "discussions")
+
+	created := 0
+	var lastErr error
+	for i, comment := range comments {
+		discussion := c.note("body", diff, comment)
+		err := c.api.Do(ctx, http.MethodPost, path, discussion, nil)
+		if err != nil && discussion["position"] != nil {
+			c.log.WithError(err).WithField("path", comment.Path).Warn("line cannot be commented on, posting as a general note")
+			err = c.api.Do(ctx, http.MethodPost, path, generalNote("body", comment), nil)
+		}
+		if err != nil {
+			c.log.WithError(err).WithFields(logrus.Fields{"index": i, "path": comment.Path}).Warn("failed to create discussion")
+			lastErr = err
+			continue
+		}
+		created++
+	}
+
+	if created == 0 && lastErr != nil {
+		return 0, fmt.Errorf("failed to create discussion: %w", lastErr)
+	}
+
+	c.log.WithField("discussions", created).Info("created merge request discussions")
+	return created, nil
+}
+
+// CreateReview adds every comment as a draft note and publishes them all at
+// once, which sends a single notification. Comments that GitLab rejects on
+// their line are retried as general notes; those rejected again are left out
+// of the review and named in the returned error. If draft notes are not
+// available at all the error wraps scm.ErrNotSupported, and if they cannot be
+// published it wraps ErrNotPublished.
+func (c *Client) CreateReview(ctx context.Context, repo string, mrNumber int, input ReviewInput) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"mr_number": mrNumber,
+		"comments":  len(input.Comments),
+	}).Info("creating merge request review")
+
+	diff, err := c.getMergeRequestDiff(ctx, repo, mrNumber)
+	if err != nil {
+		return err
+	}
+
+	path := mergeRequestPath(repo, mrNumber, "draft_notes")
+
+	var drafts []map[string]interface{}
+	for _, comment := range input.Comments {
+		drafts = append(drafts, c.note("note", diff, comment))
+	}
+	if input.Body != "" {
+		drafts = append(drafts, map[string]interface{}{"note": input.Body})
+	}
+
+	var created []int
+	var failed []string
+	for i, draft := range drafts {
+		var note struct {
+			ID int `json:"id"`
+		}
+		err := c.api.Do(ctx, http.MethodPost, path, draft, &note)
+		// Only the first draft can tell us the endpoint is missing; after that
+		// falling back would duplicate the drafts already created
+		if err != nil && i == 0 && scmclient.Unsupported(err) {
+			return fmt.Errorf("%w: %v", scm.ErrNotSupported, err)
+		}
+		if err != nil && i < len(input.Comments) && draft["position"] != nil {
+			comment := input.Comments[i]
+			c.log.WithError(err).WithField("path", comment.Path).Warn("line cannot be commented on, posting as a general note")
+			err = c.api.Do(ctx, http.MethodPost, path, generalNote("note", comment), &note)
+		}
+		if err != nil {
+			c.log.WithError(err).WithField("index", i).Warn("failed to create draft note")
+			failed = append(failed, draftName(input.Comments, i))
+			continue
+		}
+		created = append(created, note.ID)
+	}
+
+	if len(created) == 0 {
+		return fmt.Errorf("no draft notes could be created: %s", strings.Join(failed, ", "))
+	}
+
+	if err := c.api.Do(ctx, http.MethodPost, path+"/bulk_publish", nil, nil); err != nil {
+		// Unpublished drafts would be published with the next review of the
+		// token's user, so remove them before the comments are posted again
+		for _, id := range created {
+			if err := c.api.Do(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", path, id), nil, nil); err != nil {
+				c.log.WithError(err).WithField("id", id).Warn("failed to delete draft note")
+			}
+		}
+		return fmt.Errorf("%w: %v", ErrNotPublished, err)
+	}
+
+	c.log.WithField("notes", len(created)).Info("published merge request review successfully")
+	if len(failed) > 0 {
+		return fmt.Errorf("%d review comments could not be posted: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// draftName names the comment, or the review body after the comments, that
+// drafts[i] was built from
+func draftName(comments []ReviewComment, i int) string {
+	if i >= len(comments) {
+		return "review body"
+	}
+	return fmt.Sprintf("%s:%d", comments[i].Path, comments[i].Line)
+}
+
+// CreateCommitComment comments on a commit, anchored to a line of the new
+// version of a file when filePath and line are set. GitLab rejects lines it
+// cannot place, so those comments are posted as general comments that name
+// the location instead.
+func (c *Client) CreateCommitComment(ctx context.Context, repo, commitSHA, filePath string, line int, body string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":       repo,
+		"commit_sha": commitSHA,
+		"file":       filePath,
+		"line":       line,
+	}).Info("creating commit comment")
+
+	path := fmt.Sprintf("api/v4/projects/%s/repository/commits/%s/comments", url.QueryEscape(repo), commitSHA)
+
+	if filePath != "" && line > 0 {
+		payload := map[string]interface{}{
+			"note":      body,
+			"path":      filePath,
+			"line":      line,
+			"line_type": "new",
+		}
+		err := c.api.Do(ctx, http.MethodPost, path, payload, nil)
+		if err == nil {
+			return nil
+		}
+		if !scmclient.HasStatus(err, http.StatusBadRequest) {
+			return fmt.Errorf("failed to create commit comment: %w", err)
+		}
+		c.log.WithField("path", filePath).Warn("line cannot be commented on, posting as a general comment")
+		body = fmt.Sprintf("`%s:%d`\n\n%s", filePath, line, body)
+	}
+
+	if err := c.api.Do(ctx, http.MethodPost, path, map[string]interface{}{"note": body}, nil); err != nil {
+		return fmt.Errorf("failed to create commit comment: %w", err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to a merge request, creating any that do not exist
+// in the project yet
+func (c *Client) AddLabels(ctx context.Context, repo string, mrNumber int, labels []string) error {
+	return c.updateLabels(ctx, repo, mrNumber, "add_labels", labels)
+}
+
+// RemoveLabels removes labels from a merge request. Labels that are not on
+// the merge request are ignored.
+func (c *Client) RemoveLabels(ctx context.Context, repo string, mrNumber int, labels []string) error {
+	return c.updateLabels(ctx, repo, mrNumber, "remove_labels", labels)
+}
+
+func (c *Client) updateLabels(ctx context.Context, repo string, mrNumber int, field string, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"mr_number": mrNumber,
+		field:       labels,
+	}).Info("updating merge request labels")
+
+	payload := map[string]interface{}{field: strings.Join(labels, ",")}
+	if err := c.api.Do(ctx, http.MethodPut, mergeRequestPath(repo, mrNumber, ""), payload, nil); err != nil {
+		return fmt.Errorf("failed to update labels: %w", err)
+	}
+	return nil
+}
+
+// RequestReviewers adds CODEOWNERS owners as reviewers of a merge request,
+// keeping the existing reviewers. GitLab reviewers are users, so owners are
+// looked up by @username; groups, email owners and unknown users are skipped.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, mrNumber int, owners []string) error {
+	var mr struct {
+		Reviewers []struct {
+			ID int64 `json:"id"`
+		} `json:"reviewers"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, mergeRequestPath(repo, mrNumber, ""), nil, &mr); err != nil {
+		return fmt.Errorf("failed to get merge request: %w", err)
+	}
+
+	ids := make([]int64, 0, len(mr.Reviewers)+len(owners))
+	seen := make(map[int64]bool)
+	for _, reviewer := range mr.Reviewers {
+		ids = append(ids, reviewer.ID)
+		seen[reviewer.ID] = true
+	}
+
+	var added []string
+	for _, owner := range owners {
+		username, ok := strings.CutPrefix(owner, "@")
+		if !ok || strings.Contains(username, "/") {
+			c.log.WithField("owner", owner).Warn("only users can be merge request reviewers, skipping")
+			continue
+		}
+
+		var users []struct {
+			ID int64 `json:"id"`
+		}
+		path := "api/v4/users?username=" + url.QueryEscape(username)
+		if err := c.api.Do(ctx, http.MethodGet, path, nil, &users); err != nil {
+			return fmt.Errorf("failed to look up user %q: %w", username, err)
+		}
+		if len(users) == 0 {
+			c.log.WithField("owner", owner).Warn("user does not exist, skipping")
+			continue
+		}
+		if !seen[users[0].ID] {
+			seen[users[0].ID] = true
+			ids = append(ids, users[0].ID)
+			added = append(added, username)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"mr_number": mrNumber,
+		"reviewers": added,
+	}).Info("requesting merge request reviewers")
+
+	payload := map[string]interface{}{"reviewer_ids": ids}
+	if err := c.api.Do(ctx, http.MethodPut, mergeRequestPath(repo, mrNumber, ""), payload, nil); err != nil {
+		return fmt.Errorf("failed to request reviewers: %w", err)
+	}
+	return nil
+}
+
+// UpdateDescription replaces the description of a merge request
+func (c *Client) UpdateDescription(ctx context.Context, repo string, mrNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"mr_number": mrNumber,
+	}).Info("updating merge request description")
+
+	payload := map[string]interface{}{"description": description}
+	if err := c.api.Do(ctx, http.MethodPut, mergeRequestPath(repo, mrNumber, ""), payload, nil); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// note builds a discussion or draft note payload. Comments on files outside
+// the merge request cannot be positioned, so they become general notes that
+// name the location instead.
+func (c *Client) note(field string, diff *mergeRequestDiff, comment ReviewComment) map[string]interface{} {
+	position := diff.position(comment.Path, comment.Line)
+	if position == nil {
+		c.log.WithField("path", comment.Path).Warn("file is not part of the merge request diff, posting as a general note")
+		return generalNote(field, comment)
+	}
+	return map[string]interface{}{
+		field:      comment.Body,
+		"position": position,
+	}
+}
+
+// generalNote builds an unpositioned note that names the location in its body
+func generalNote(field string, comment ReviewComment) map[string]interface{} {
+	return map[string]interface{}{
+		field: fmt.Sprintf("`%s:%d`\n\n%s", comment.Path, comment.Line, comment.Body),
+	}
+}
+
+func mergeRequestPath(repo string, mrNumber int, suffix string) string {
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests/%d", url.QueryEscape(repo), mrNumber)
+	if suffix != "" {
+		path += "/" + suffix
+	}
+	return path
+}
@@ -0,0 +1,456 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/gitlab"
+	"github.com/sirupsen/logrus"
+)
+
+type request struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+func newTestClient(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*Client, *[]request) {
+	t.Helper()
+
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.EscapedPath()}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	scmClient, err := gitlab.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(scmClient), &requests
+}
+
+const (
+	mergeRequestJSON = `{"diff_refs": {"base_sha": "base", "start_sha": "start", "head_sha": "head"}}`
+	diffsJSON        = `[{"old_path": "a.go", "new_path": "a.go", "diff": "@@ -1,2 +1,3 @@\n package a\n+// added\n \n"}]`
+	basePath         = "/api/v4/projects/group%2Fproject/merge_requests/4"
+)
+
+// serveMergeRequest answers the merge request and diffs lookups, reporting
+// whether the request was one of them
+func serveMergeRequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if strings.HasSuffix(r.URL.Path, "/diffs") {
+		_, _ = w.Write([]byte(diffsJSON))
+	} else {
+		_, _ = w.Write([]byte(mergeRequestJSON))
+	}
+	return true
+}
+
+// posts returns the POST requests, skipping the lookups
+func posts(requests []request) []request {
+	var out []request
+	for _, req := range requests {
+		if req.method == http.MethodPost {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+func TestGetDiffsFallsBackToChanges(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/diffs") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"changes": ` + diffsJSON + `}`))
+	})
+
+	diffs, err := c.GetDiffs(context.Background(), "group/project", 4)
+	if err != nil {
+		t.Fatalf("GetDiffs failed: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].NewPath != "a.go" {
+		t.Errorf("unexpected diffs %v", diffs)
+	}
+	if last := (*requests)[len(*requests)-1]; last.path != basePath+"/changes" {
+		t.Errorf("unexpected fallback request %s", last.path)
+	}
+}
+
+func TestCreateDiscussions(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	created, err := c.CreateDiscussions(context.Background(), "group/project", 4, []ReviewComment{
+		{Path: "a.go", Line: 2, Body: "added"},
+		{Path: "a.go", Line: 3, Body: "context"},
+		{Path: "other.go", Line: 7, Body: "elsewhere"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDiscussions failed: %v", err)
+	}
+	if created != 3 {
+		t.Errorf("created %d discussions, want 3", created)
+	}
+
+	reqs := posts(*requests)
+	if len(reqs) != 3 {
+		t.Fatalf("got %d discussion requests, want 3", len(reqs))
+	}
+	if reqs[0].path != basePath+"/discussions" {
+		t.Errorf("unexpected discussion path %s", reqs[0].path)
+	}
+
+	added, _ := reqs[0].body["position"].(map[string]interface{})
+	if _, ok := added["old_line"]; ok || added["new_line"] != float64(2) || added["base_sha"] != "base" {
+		t.Errorf("unexpected position for added line %v", added)
+	}
+	unchanged, _ := reqs[1].body["position"].(map[string]interface{})
+	if unchanged["old_line"] != float64(2) || unchanged["new_line"] != float64(3) {
+		t.Errorf("unexpected position for context line %v", unchanged)
+	}
+	if _, ok := reqs[2].body["position"]; ok || !strings.HasPrefix(reqs[2].body["body"].(string), "`other.go:7`") {
+		t.Errorf("file outside the diff should be a general note: %v", reqs[2].body)
+	}
+}
+
+func TestCreateDiscussionError(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	err := c.CreateDiscussion(context.Background(), "group/project", 4, ReviewComment{Path: "a.go", Line: 2, Body: "fix"})
+	if err == nil {
+		t.Fatal("CreateDiscussion should fail when GitLab rejects the note")
+	}
+}
+
+func TestCreateReview(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	err := c.CreateReview(context.Background(), "group/project", 4, ReviewInput{
+		Body: "summary",
+		Comments: []ReviewComment{
+			{Path: "a.go", Line: 2, Body: "first"},
+			{Path: "a.go", Line: 3, Body: "second"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	reqs := posts(*requests)
+	if len(reqs) != 4 {
+		t.Fatalf("got %d POST requests, want 4", len(reqs))
+	}
+	for _, req := range reqs[:3] {
+		if req.path != basePath+"/draft_notes" {
+			t.Errorf("unexpected draft request %s", req.path)
+		}
+	}
+
+	position, _ := reqs[1].body["position"].(map[string]interface{})
+	if position["head_sha"] != "head" || position["new_path"] != "a.go" || position["old_line"] != float64(2) {
+		t.Errorf("unexpected position %v", position)
+	}
+	if _, ok := reqs[2].body["position"]; ok || reqs[2].body["note"] != "summary" {
+		t.Errorf("review body should be a general note: %v", reqs[2].body)
+	}
+	if reqs[3].path != basePath+"/draft_notes/bulk_publish" {
+		t.Errorf("unexpected publish request %s", reqs[3].path)
+	}
+}
+
+func TestCreateReviewRetriesRejectedNotes(t *testing.T) {
+	drafts := 0
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/draft_notes") {
+			drafts++
+			if drafts == 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	err := c.CreateReview(context.Background(), "group/project", 4, ReviewInput{
+		Comments: []ReviewComment{
+			{Path: "a.go", Line: 2, Body: "ok"},
+			{Path: "a.go", Line: 999, Body: "outside the diff"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	reqs := posts(*requests)
+	if len(reqs) != 4 {
+		t.Fatalf("got %d requests, want 2 drafts, the retry and the publish", len(reqs))
+	}
+	if _, ok := reqs[2].body["position"]; ok || !strings.HasPrefix(reqs[2].body["note"].(string), "`a.go:999`") {
+		t.Errorf("rejected draft should be retried as a general note: %v", reqs[2].body)
+	}
+	if !strings.HasSuffix(reqs[3].path, "/bulk_publish") {
+		t.Errorf("drafts should still be published, last request was %s", reqs[3].path)
+	}
+}
+
+func TestCreateReviewReportsNotesNotPosted(t *testing.T) {
+	drafts := 0
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/draft_notes") {
+			drafts++
+			if drafts > 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	err := c.CreateReview(context.Background(), "group/project", 4, ReviewInput{
+		Comments: []ReviewComment{
+			{Path: "a.go", Line: 2, Body: "ok"},
+			{Path: "a.go", Line: 999, Body: "outside the diff"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "a.go:999") || strings.Contains(err.Error(), "a.go:2") {
+		t.Errorf("expected an error naming a.go:999, got %v", err)
+	}
+	if errors.Is(err, ErrNotPublished) || errors.Is(err, scm.ErrNotSupported) {
+		t.Errorf("published drafts must not be posted again: %v", err)
+	}
+
+	reqs := *requests
+	if last := reqs[len(reqs)-1]; !strings.HasSuffix(last.path, "/bulk_publish") {
+		t.Errorf("drafts should still be published, last request was %s", last.path)
+	}
+}
+
+func TestCreateDiscussionsRetriesRejectedNotes(t *testing.T) {
+	discussions := 0
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		discussions++
+		if discussions == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	created, err := c.CreateDiscussions(context.Background(), "group/project", 4, []ReviewComment{
+		{Path: "a.go", Line: 999, Body: "outside the diff"},
+	})
+	if err != nil || created != 1 {
+		t.Fatalf("CreateDiscussions = %d, %v, want 1 created", created, err)
+	}
+	if reqs := posts(*requests); len(reqs) != 2 || !strings.HasPrefix(reqs[1].body["body"].(string), "`a.go:999`") {
+		t.Errorf("rejected discussion should be retried as a general note: %v", reqs)
+	}
+}
+
+func TestCreateReviewNotSupported(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	err := c.CreateReview(context.Background(), "group/project", 4, ReviewInput{
+		Body:     "summary",
+		Comments: []ReviewComment{{Path: "a.go", Line: 2, Body: "fix"}},
+	})
+	if !errors.Is(err, scm.ErrNotSupported) {
+		t.Errorf("expected scm.ErrNotSupported, got %v", err)
+	}
+	if len(posts(*requests)) != 1 {
+		t.Errorf("should stop after the first draft, got %d drafts", len(posts(*requests)))
+	}
+}
+
+func TestCreateReviewDeletesUnpublishedDrafts(t *testing.T) {
+	drafts := 0
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if serveMergeRequest(w, r) {
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/bulk_publish"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Method == http.MethodPost:
+			drafts++
+			_, _ = fmt.Fprintf(w, `{"id": %d}`, drafts)
+		}
+	})
+
+	err := c.CreateReview(context.Background(), "group/project", 4, ReviewInput{
+		Body:     "summary",
+		Comments: []ReviewComment{{Path: "a.go", Line: 2, Body: "fix"}},
+	})
+	if !errors.Is(err, ErrNotPublished) {
+		t.Fatalf("expected ErrNotPublished, got %v", err)
+	}
+
+	var deleted []string
+	for _, req := range *requests {
+		if req.method == http.MethodDelete {
+			deleted = append(deleted, req.path)
+		}
+	}
+	if len(deleted) != 2 || deleted[0] != basePath+"/draft_notes/1" || deleted[1] != basePath+"/draft_notes/2" {
+		t.Errorf("unexpected draft deletions %v", deleted)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.AddLabels(context.Background(), "group/project", 4, []string{"ai-reviewed", "review::done"}); err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	if err := c.RemoveLabels(context.Background(), "group/project", 4, []string{"needs-attention"}); err != nil {
+		t.Fatalf("RemoveLabels failed: %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+	add, remove := (*requests)[0], (*requests)[1]
+	if add.method != http.MethodPut || add.path != basePath || add.body["add_labels"] != "ai-reviewed,review::done" {
+		t.Errorf("unexpected add request %s %s %v", add.method, add.path, add.body)
+	}
+	if remove.body["remove_labels"] != "needs-attention" {
+		t.Errorf("unexpected remove request %v", remove.body)
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.UpdateDescription(context.Background(), "group/project", 4, "new description"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	if req.method != http.MethodPut || req.path != basePath || req.body["description"] != "new description" {
+		t.Errorf("unexpected request %s %s %v", req.method, req.path, req.body)
+	}
+}
+
+func TestRequestReviewers(t *testing.T) {
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/users":
+			if r.URL.Query().Get("username") == "alice" {
+				_, _ = w.Write([]byte(`[{"id": 12}]`))
+			} else {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"reviewers": [{"id": 3}]}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+
+	owners := []string{"@alice", "@ghost", "@group/backend", "dev@example.com"}
+	if err := c.RequestReviewers(context.Background(), "group/project", 4, owners); err != nil {
+		t.Fatalf("RequestReviewers failed: %v", err)
+	}
+
+	update := (*requests)[len(*requests)-1]
+	if update.method != http.MethodPut || update.path != basePath {
+		t.Fatalf("unexpected update %s %s", update.method, update.path)
+	}
+	ids, _ := update.body["reviewer_ids"].([]interface{})
+	if len(ids) != 2 || ids[0] != float64(3) || ids[1] != float64(12) {
+		t.Errorf("reviewer_ids = %v, want [3 12]", ids)
+	}
+}
+
+func TestCreateCommitComment(t *testing.T) {
+	calls := 0
+	c, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			// The second comment is on a line outside the diff
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "line_code is invalid"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if err := c.CreateCommitComment(context.Background(), "group/project", "abc123", "a.go", 3, "fix"); err != nil {
+		t.Fatalf("CreateCommitComment failed: %v", err)
+	}
+	if err := c.CreateCommitComment(context.Background(), "group/project", "abc123", "a.go", 99, "far away"); err != nil {
+		t.Fatalf("CreateCommitComment should fall back to a general comment: %v", err)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(*requests))
+	}
+	inline := (*requests)[0]
+	if inline.path != "/api/v4/projects/group%2Fproject/repository/commits/abc123/comments" {
+		t.Errorf("unexpected path %s", inline.path)
+	}
+	if inline.body["path"] != "a.go" || inline.body["line_type"] != "new" {
+		t.Errorf("unexpected inline payload %v", inline.body)
+	}
+	fallback := (*requests)[2]
+	if _, ok := fallback.body["path"]; ok || fallback.body["note"] != "`a.go:99`\n\nfar away" {
+		t.Errorf("unexpected fallback payload %v", fallback.body)
+	}
+}
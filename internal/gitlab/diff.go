@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the diff of one file in a merge request
+type FileDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// lineMap maps line numbers of the new file to the old file for one diff
+type lineMap struct {
+	// lines inside hunks; 0 marks a line added by the change
+	hunkLines map[int]int
+	// offsets between new and old line numbers after each hunk, in order
+	hunks []hunkOffset
+}
+
+type hunkOffset struct {
+	newStart int
+	delta    int
+}
+
+// parseDiff reads the hunks of a unified diff
+func parseDiff(diff string) *lineMap {
+	m := &lineMap{hunkLines: make(map[int]int)}
+
+	oldLine, newLine := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		if match := hunkHeader.FindStringSubmatch(line); match != nil {
+			oldLine, _ = strconv.Atoi(match[1])
+			newLine, _ = strconv.Atoi(match[2])
+			m.hunks = append(m.hunks, hunkOffset{newStart: newLine})
+			inHunk = true
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			m.hunkLines[newLine] = 0
+			newLine++
+		case '-':
+			oldLine++
+		case ' ':
+			m.hunkLines[newLine] = oldLine
+			oldLine++
+			newLine++
+		default:
+			// "\ No newline at end of file" and similar markers
+			continue
+		}
+		m.hunks[len(m.hunks)-1].delta = newLine - oldLine
+	}
+
+	return m
+}
+
+// oldLine returns the old line number of a new line, or 0 when the line was
+// added. Lines outside every hunk are unchanged and shifted by the hunks
+// before them.
+func (m *lineMap) oldLine(newLine int) int {
+	if old, ok := m.hunkLines[newLine]; ok {
+		return old
+	}
+
+	delta := 0
+	for _, h := range m.hunks {
+		if h.newStart > newLine {
+			break
+		}
+		delta = h.delta
+	}
+	return newLine - delta
+}
+
+// mergeRequestDiff is everything needed to position notes on a merge request
+type mergeRequestDiff struct {
+	refs  *DiffRefs
+	files map[string]*FileDiff
+}
+
+// position builds a text position for a line of the new file, or nil when
+// the file is not part of the merge request. Added lines only carry
+// new_line; context and unchanged lines carry both line numbers, as GitLab
+// requires.
+func (d *mergeRequestDiff) position(path string, line int) map[string]interface{} {
+	file, ok := d.files[path]
+	if !ok || file.DeletedFile {
+		return nil
+	}
+
+	position := map[string]interface{}{
+		"position_type": "text",
+		"base_sha":      d.refs.BaseSHA,
+		"start_sha":     d.refs.StartSHA,
+		"head_sha":      d.refs.HeadSHA,
+		"old_path":      file.OldPath,
+		"new_path":      file.NewPath,
+		"new_line":      line,
+	}
+	if !file.NewFile {
+		if old := parseDiff(file.Diff).oldLine(line); old > 0 {
+			position["old_line"] = old
+		}
+	}
+	return position
+}
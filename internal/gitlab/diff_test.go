@@ -0,0 +1,83 @@
+package gitlab
+
+import "testing"
+
+const sampleDiff = `@@ -3,6 +3,7 @@ import (
+ 	"fmt"
+ 	"os"
++	"strings"
+ )
+ 
+-func old() {}
++func main() {}
+ 
+@@ -20,3 +21,2 @@ func helper() {
+ 	a := 1
+-	b := 2
+ 	return
+`
+
+func TestOldLine(t *testing.T) {
+	m := parseDiff(sampleDiff)
+
+	tests := []struct {
+		name    string
+		newLine int
+		want    int
+	}{
+		{"before first hunk", 1, 1},
+		{"context line", 3, 3},
+		{"added line", 5, 0},
+		{"context after addition", 6, 5},
+		{"replaced line", 8, 0},
+		{"between hunks", 15, 14},
+		{"context in second hunk", 21, 20},
+		{"context after deletion", 22, 22},
+		{"after last hunk", 30, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.oldLine(tt.newLine); got != tt.want {
+				t.Errorf("oldLine(%d) = %d, want %d", tt.newLine, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	d := &mergeRequestDiff{
+		refs: &DiffRefs{BaseSHA: "base", StartSHA: "start", HeadSHA: "head"},
+		files: map[string]*FileDiff{
+			"main.go":  {OldPath: "main.go", NewPath: "main.go", Diff: sampleDiff},
+			"new.go":   {OldPath: "new.go", NewPath: "new.go", Diff: "@@ -0,0 +1,2 @@\n+package x\n+\n", NewFile: true},
+			"moved.go": {OldPath: "old.go", NewPath: "moved.go", Diff: sampleDiff},
+			"gone.go":  {OldPath: "gone.go", NewPath: "gone.go", DeletedFile: true},
+		},
+	}
+
+	added := d.position("main.go", 5)
+	if _, ok := added["old_line"]; ok || added["new_line"] != 5 {
+		t.Errorf("added line should only have new_line: %v", added)
+	}
+
+	unchanged := d.position("main.go", 6)
+	if unchanged["old_line"] != 5 || unchanged["new_line"] != 6 || unchanged["head_sha"] != "head" {
+		t.Errorf("unexpected context position %v", unchanged)
+	}
+
+	if pos := d.position("new.go", 1); pos["old_line"] != nil {
+		t.Errorf("new file should only have new_line: %v", pos)
+	}
+
+	if pos := d.position("moved.go", 3); pos["old_path"] != "old.go" || pos["new_path"] != "moved.go" {
+		t.Errorf("renamed file should keep both paths: %v", pos)
+	}
+
+	if pos := d.position("gone.go", 1); pos != nil {
+		t.Errorf("deleted file should have no position: %v", pos)
+	}
+	if pos := d.position("other.go", 1); pos != nil {
+		t.Errorf("file outside the diff should have no position: %v", pos)
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// labelsPerPage is the page size used when listing labels
+const labelsPerPage = 100
+
 // Config holds configuration for the Harness Code client
 type Config struct {
 	Endpoint  string
@@ -67,35 +70,29 @@ func NewClient(cfg Config) (*Client, error) {
 	}, nil
 }
 
-// CreateComment creates a comment on a pull request
-func (c *Client) CreateComment(ctx context.Context, repo string, prNumber int, body string) error {
+// CreateComment creates a comment on a pull request and returns its ID
+func (c *Client) CreateComment(ctx context.Context, repo string, prNumber int, body string) (int, error) {
 	c.log.WithFields(logrus.Fields{
 		"repo":      repo,
 		"pr_number": prNumber,
 	}).Info("creating PR comment")
 
-	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/comments", prNumber))
-
 	payload := map[string]interface{}{
 		"text": body,
 	}
 
-	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	id, err := c.postComment(ctx, repo, prNumber, payload)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if err := c.checkResponse(resp); err != nil {
-		return fmt.Errorf("failed to create comment: %w", err)
+		return 0, fmt.Errorf("failed to create comment: %w", err)
 	}
 
-	c.log.Info("created PR comment successfully")
-	return nil
+	c.log.WithField("comment_id", id).Info("created PR comment successfully")
+	return id, nil
 }
 
-// CreateInlineComment creates an inline comment on a specific file/line
-func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber int, filePath string, line int, body string) error {
+// CreateInlineComment creates an inline comment on a specific file/line and
+// returns its ID
+func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber int, filePath string, line int, body string) (int, error) {
 	c.log.WithFields(logrus.Fields{
 		"repo":      repo,
 		"pr_number": prNumber,
@@ -106,11 +103,9 @@ func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber
 	// First, get PR details to obtain commit SHAs (required for code comments)
 	pr, err := c.getPR(ctx, repo, prNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get PR details: %w", err)
+		return 0, fmt.Errorf("failed to get PR details: %w", err)
 	}
 
-	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/comments", prNumber))
-
 	payload := map[string]interface{}{
 		"text":              body,
 		"path":              filePath,
@@ -120,25 +115,49 @@ func (c *Client) CreateInlineComment(ctx context.Context, repo string, prNumber
 		"target_commit_sha": pr.MergeBaseSHA,
 	}
 
+	id, err := c.postComment(ctx, repo, prNumber, payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create inline comment: %w", err)
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"file": filePath,
+		"line": line,
+	}).Info("created inline comment successfully")
+	return id, nil
+}
+
+// postComment posts a pull request comment and returns its ID
+func (c *Client) postComment(ctx context.Context, repo string, prNumber int, payload map[string]interface{}) (int, error) {
+	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/comments", prNumber))
+
 	resp, err := c.do(ctx, http.MethodPost, path, payload)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if err := c.checkResponse(resp); err != nil {
-		return fmt.Errorf("failed to create inline comment: %w", err)
+		return 0, err
 	}
 
-	c.log.WithFields(logrus.Fields{
-		"file": filePath,
-		"line": line,
-	}).Info("created inline comment successfully")
-	return nil
+	var out struct {
+		ID int `json:"id"`
+	}
+	// The ID is informational, so an unexpected body is not an error
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return out.ID, nil
 }
 
-// prInfo holds minimal PR info needed for code comments
+// prInfo holds the pull request fields the plugin needs
 type prInfo struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	State        string `json:"state"`
+	Merged       *int64 `json:"merged"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
 	SourceSHA    string `json:"source_sha"`
 	MergeBaseSHA string `json:"merge_base_sha"`
 }
@@ -180,31 +199,50 @@ func (c *Client) getPR(ctx context.Context, repo string, prNumber int) (*prInfo,
 	return &pr, nil
 }
 
-// GetPRDetails retrieves PR details including commit SHAs
-func (c *Client) GetPRDetails(ctx context.Context, repo string, prNumber int) (*PRDetails, error) {
+// listPRs lists one page of the repository's pull requests. An empty state
+// lists pull requests in every state.
+func (c *Client) listPRs(ctx context.Context, repo, state string, page, limit int) ([]prInfo, error) {
 	c.log.WithFields(logrus.Fields{
-		"repo":      repo,
-		"pr_number": prNumber,
-	}).Info("getting PR details for batch comments")
+		"repo":  repo,
+		"state": state,
+		"page":  page,
+	}).Debug("listing PRs")
 
-	pr, err := c.getPR(ctx, repo, prNumber)
+	query := url.Values{}
+	if state != "" {
+		query.Set("state", state)
+	}
+	if page > 0 {
+		query.Set("page", fmt.Sprint(page))
+	}
+	if limit > 0 {
+		query.Set("limit", fmt.Sprint(limit))
+	}
+	path := c.apiPath(repo, "pullreq")
+	if len(query) > 0 {
+		path = withQuery(path, query.Encode())
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	c.log.WithFields(logrus.Fields{
-		"source_sha": pr.SourceSHA,
-		"target_sha": pr.MergeBaseSHA,
-	}).Info("retrieved PR commit SHAs")
+	if err := c.checkResponse(resp); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
 
-	return &PRDetails{
-		SourceSHA: pr.SourceSHA,
-		TargetSHA: pr.MergeBaseSHA,
-	}, nil
+	var prs []prInfo
+	if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
 }
 
-// CreateReviewComment creates a review comment on a specific file/line
-func (c *Client) CreateReviewComment(ctx context.Context, repo string, prNumber int, filePath string, lineStart, lineEnd int, reviewType, reviewText, sourceSHA, targetSHA string) error {
+// CreateReviewComment creates a review comment on a specific file/line range
+// and returns its ID
+func (c *Client) CreateReviewComment(ctx context.Context, repo string, prNumber int, filePath string, lineStart, lineEnd int, reviewType, reviewText, sourceSHA, targetSHA string) (int, error) {
 	c.log.WithFields(logrus.Fields{
 		"repo":       repo,
 		"pr_number":  prNumber,
@@ -214,8 +252,6 @@ func (c *Client) CreateReviewComment(ctx context.Context, repo string, prNumber
 		"type":       reviewType,
 	}).Debug("creating review comment")
 
-	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/comments", prNumber))
-
 	// Format the comment text with type prefix
 	commentText := reviewText
 	if reviewType != "" {
@@ -231,14 +267,9 @@ func (c *Client) CreateReviewComment(ctx context.Context, repo string, prNumber
 		"target_commit_sha": targetSHA,
 	}
 
-	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	id, err := c.postComment(ctx, repo, prNumber, payload)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if err := c.checkResponse(resp); err != nil {
-		return fmt.Errorf("failed to create review comment: %w", err)
+		return 0, fmt.Errorf("failed to create review comment: %w", err)
 	}
 
 	c.log.WithFields(logrus.Fields{
@@ -247,27 +278,116 @@ func (c *Client) CreateReviewComment(ctx context.Context, repo string, prNumber
 		"line_end":   lineEnd,
 		"type":       reviewType,
 	}).Info("created review comment successfully")
+	return id, nil
+}
+
+// SubmitReview submits a review decision (approved, changereq or reviewed)
+// on a pull request for the given commit, or its source commit when empty.
+// Decisions carry no message, so a body is posted as a comment first.
+func (c *Client) SubmitReview(ctx context.Context, repo string, prNumber int, commitSHA, decision, body string) error {
+	if commitSHA == "" {
+		pr, err := c.getPR(ctx, repo, prNumber)
+		if err != nil {
+			return err
+		}
+		commitSHA = pr.SourceSHA
+	}
+	if body != "" {
+		if _, err := c.CreateComment(ctx, repo, prNumber, body); err != nil {
+			return err
+		}
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"repo":       repo,
+		"pr_number":  prNumber,
+		"commit_sha": commitSHA,
+		"decision":   decision,
+	}).Info("submitting PR review")
+
+	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/reviews", prNumber))
+
+	payload := map[string]interface{}{
+		"commit_sha": commitSHA,
+		"decision":   decision,
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to submit review: %w", err)
+	}
+
+	c.log.WithField("decision", decision).Info("submitted PR review successfully")
 	return nil
 }
 
+// CheckInput describes a commit status check. Details is shown as the
+// check's report in the given payload kind (markdown or raw); Started and
+// Ended are optional.
+type CheckInput struct {
+	Identifier  string
+	State       string
+	Summary     string
+	Link        string
+	PayloadKind string // markdown, raw
+	Details     string
+	Started     time.Time
+	Ended       time.Time
+}
+
 // CreateStatus creates a commit status check
 func (c *Client) CreateStatus(ctx context.Context, repo, commitSHA, state, statusContext, description, targetURL string) error {
+	return c.CreateCheck(ctx, repo, commitSHA, CheckInput{
+		Identifier: statusContext,
+		State:      state,
+		Summary:    description,
+		Link:       targetURL,
+	})
+}
+
+// CreateCheck creates or updates a commit status check. Reporting the same
+// identifier again updates the check, so a running check can be finalized
+// later.
+func (c *Client) CreateCheck(ctx context.Context, repo, commitSHA string, input CheckInput) error {
 	c.log.WithFields(logrus.Fields{
 		"repo":       repo,
 		"commit_sha": commitSHA,
-		"state":      state,
-		"context":    statusContext,
+		"state":      input.State,
+		"context":    input.Identifier,
 	}).Info("creating commit status")
 
 	path := c.apiPath(repo, fmt.Sprintf("commits/%s/checks", commitSHA))
 
 	payload := map[string]interface{}{
-		"identifier": statusContext,
-		"status":     mapState(state),
-		"summary":    description,
+		"identifier": input.Identifier,
+		"status":     mapState(input.State),
+		"summary":    input.Summary,
+	}
+	if input.Link != "" {
+		payload["link"] = input.Link
+	}
+	if input.Details != "" {
+		kind := input.PayloadKind
+		if kind == "" {
+			kind = "markdown"
+		}
+		payload["payload"] = map[string]interface{}{
+			"kind":    kind,
+			"version": "1",
+			"data":    map[string]interface{}{"details": input.Details},
+		}
+	}
+	// Check timestamps are unix milliseconds
+	if !input.Started.IsZero() {
+		payload["started"] = input.Started.UnixMilli()
 	}
-	if targetURL != "" {
-		payload["link"] = targetURL
+	if !input.Ended.IsZero() {
+		payload["ended"] = input.Ended.UnixMilli()
 	}
 
 	resp, err := c.do(ctx, http.MethodPut, path, payload)
@@ -281,12 +401,273 @@ func (c *Client) CreateStatus(ctx context.Context, repo, commitSHA, state, statu
 	}
 
 	c.log.WithFields(logrus.Fields{
-		"status":  state,
-		"context": statusContext,
+		"status":  input.State,
+		"context": input.Identifier,
 	}).Info("created commit status successfully")
 	return nil
 }
 
+// CheckStarted returns when a check on a commit started, or the zero time
+// when the check does not exist or has no start time
+func (c *Client) CheckStarted(ctx context.Context, repo, commitSHA, identifier string) (time.Time, error) {
+	path := withQuery(c.apiPath(repo, fmt.Sprintf("commits/%s/checks", commitSHA)), "query="+url.QueryEscape(identifier))
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return time.Time{}, fmt.Errorf("failed to list checks: %w", err)
+	}
+
+	var checks []struct {
+		Identifier string `json:"identifier"`
+		Started    int64  `json:"started"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&checks); err != nil {
+		return time.Time{}, fmt.Errorf("failed to list checks: %w", err)
+	}
+
+	for _, check := range checks {
+		if check.Identifier == identifier && check.Started > 0 {
+			return time.UnixMilli(check.Started), nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// UpdateDescription replaces the description of a pull request. Harness Code
+// requires a title on update, so the current one is read first and sent back
+// unchanged.
+func (c *Client) UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+	}).Info("updating PR description")
+
+	pr, err := c.getPR(ctx, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"title":       pr.Title,
+		"description": description,
+	}
+
+	path := c.apiPath(repo, fmt.Sprintf("pullreq/%d", prNumber))
+	resp, err := c.do(ctx, http.MethodPatch, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to update description: %w", err)
+	}
+	return nil
+}
+
+// RequestReviewers adds CODEOWNERS owners as reviewers of a pull request.
+// Harness Code adds reviewers by principal ID, so owners (email addresses or
+// user IDs, with or without a leading @) are looked up first; unknown owners
+// are logged and skipped.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, prNumber int, owners []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"owners":    owners,
+	}).Info("requesting PR reviewers")
+
+	for _, owner := range owners {
+		id, err := c.principalID(ctx, strings.TrimPrefix(owner, "@"))
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			c.log.WithField("owner", owner).Warn("user does not exist, skipping")
+			continue
+		}
+
+		path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/reviewers", prNumber))
+		resp, err := c.do(ctx, http.MethodPut, path, map[string]interface{}{"reviewer_id": id})
+		if err != nil {
+			return err
+		}
+		err = c.checkResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add reviewer %q: %w", owner, err)
+		}
+	}
+	return nil
+}
+
+// principalID returns the ID of the user with the given email address or
+// user ID, or 0 when there is none
+func (c *Client) principalID(ctx context.Context, owner string) (int64, error) {
+	query := url.Values{}
+	query.Set("query", owner)
+	query.Set("type", "user")
+	path := withQuery(c.accountPath("principals"), query.Encode())
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return 0, fmt.Errorf("failed to look up user %q: %w", owner, err)
+	}
+
+	var principals []struct {
+		ID    int64  `json:"id"`
+		UID   string `json:"uid"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&principals); err != nil {
+		return 0, err
+	}
+	for _, principal := range principals {
+		if strings.EqualFold(principal.Email, owner) || principal.UID == owner {
+			return principal.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// AddLabels assigns existing labels to a pull request. Harness Code assigns
+// labels by ID, so the names (label keys) are looked up first, including
+// labels inherited from the project, organization and account. Unknown
+// labels are logged and skipped.
+func (c *Client) AddLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("adding PR labels")
+
+	ids, err := c.labelIDs(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		id, ok := ids[label]
+		if !ok {
+			c.log.WithField("label", label).Warn("label does not exist, skipping")
+			continue
+		}
+
+		path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/labels", prNumber))
+		resp, err := c.do(ctx, http.MethodPut, path, map[string]interface{}{"label_id": id})
+		if err != nil {
+			return err
+		}
+		err = c.checkResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add label %q: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// RemoveLabels unassigns labels from a pull request. Labels that do not exist
+// or are not assigned are ignored.
+func (c *Client) RemoveLabels(ctx context.Context, repo string, prNumber int, labels []string) error {
+	c.log.WithFields(logrus.Fields{
+		"repo":      repo,
+		"pr_number": prNumber,
+		"labels":    labels,
+	}).Info("removing PR labels")
+
+	ids, err := c.labelIDs(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		id, ok := ids[label]
+		if !ok {
+			continue
+		}
+
+		path := c.apiPath(repo, fmt.Sprintf("pullreq/%d/labels/%d", prNumber, id))
+		resp, err := c.do(ctx, http.MethodDelete, path, nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			continue
+		}
+		err = c.checkResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to remove label %q: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// labelIDs maps the keys of the labels available to the repository to their
+// IDs
+func (c *Client) labelIDs(ctx context.Context, repo string) (map[string]int64, error) {
+	ids := make(map[string]int64)
+	for page := 1; ; page++ {
+		path := withQuery(c.apiPath(repo, "labels"), fmt.Sprintf("inherited=true&page=%d&limit=%d", page, labelsPerPage))
+
+		resp, err := c.do(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var labels []struct {
+			ID  int64  `json:"id"`
+			Key string `json:"key"`
+		}
+		err = c.checkResponse(resp)
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(&labels)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+
+		for _, label := range labels {
+			ids[label.Key] = label.ID
+		}
+		if len(labels) < labelsPerPage {
+			return ids, nil
+		}
+	}
+}
+
+// withQuery appends query parameters to a path that may already have some
+func withQuery(path, query string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + query
+	}
+	return path + "?" + query
+}
+
+// accountPath builds the URL of an account-level endpoint
+func (c *Client) accountPath(suffix string) string {
+	path := fmt.Sprintf("%s/gateway/code/api/v1/%s", c.baseURL, suffix)
+	if c.config.AccountID != "" {
+		query := url.Values{}
+		query.Set("routingId", c.config.AccountID)
+		query.Set("accountIdentifier", c.config.AccountID)
+		path = fmt.Sprintf("%s?%s", path, query.Encode())
+	}
+	return path
+}
+
 func (c *Client) apiPath(repo, suffix string) string {
 	// Parse repo format to determine level:
 	// - "reponame"         -> project level (use org + project from config)
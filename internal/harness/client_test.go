@@ -1,8 +1,14 @@
 package harness
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -92,3 +98,244 @@ func TestMapState(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateCheckPayload(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var method, path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	started := time.Unix(1700000000, 0)
+	err := c.CreateCheck(context.Background(), "repo", "abc", CheckInput{
+		Identifier: "ai-review",
+		State:      "failure",
+		Summary:    "2 findings",
+		Details:    "| Severity |",
+		Started:    started,
+		Ended:      started.Add(90 * time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateCheck failed: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/gateway/code/api/v1/repos/repo/commits/abc/checks" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if body["status"] != "failure" || body["identifier"] != "ai-review" {
+		t.Errorf("unexpected check %v", body)
+	}
+	if body["started"] != float64(1700000000000) || body["ended"] != float64(1700000090000) {
+		t.Errorf("timestamps should be unix milliseconds: %v %v", body["started"], body["ended"])
+	}
+	payload := body["payload"].(map[string]interface{})
+	data := payload["data"].(map[string]interface{})
+	if payload["kind"] != "markdown" || data["details"] != "| Severity |" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+}
+
+func TestCheckStarted(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		_, _ = w.Write([]byte(`[{"identifier": "ai-review-2", "started": 1}, {"identifier": "ai-review", "started": 1700000000000}]`))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	started, err := c.CheckStarted(context.Background(), "repo", "abc", "ai-review")
+	if err != nil {
+		t.Fatalf("CheckStarted failed: %v", err)
+	}
+	if !started.Equal(time.UnixMilli(1700000000000)) || query != "ai-review" {
+		t.Errorf("started = %v with query %q", started, query)
+	}
+
+	started, err = c.CheckStarted(context.Background(), "repo", "abc", "missing")
+	if err != nil || !started.IsZero() {
+		t.Errorf("a missing check should have no start time: %v, %v", started, err)
+	}
+}
+
+func TestCreateStatusOmitsPayload(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	if err := c.CreateStatus(context.Background(), "repo", "abc", "running", "build", "in progress", ""); err != nil {
+		t.Fatalf("CreateStatus failed: %v", err)
+	}
+	for _, key := range []string{"payload", "started", "ended", "link"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("%s should be omitted: %v", key, body)
+		}
+	}
+	if body["status"] != "running" {
+		t.Errorf("unexpected status %v", body["status"])
+	}
+}
+
+func TestLabels(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var assigned []interface{}
+	var removed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gateway/code/api/v1/repos/repo/labels":
+			if r.URL.Query().Get("inherited") != "true" || r.URL.Query().Get("accountIdentifier") != "acc" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"id": 7, "key": "ai-reviewed"}, {"id": 8, "key": "needs-attention"}]`))
+		case r.Method == http.MethodPut && r.URL.Path == "/gateway/code/api/v1/repos/repo/pullreq/3/labels":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assigned = append(assigned, body["label_id"])
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodDelete:
+			removed = append(removed, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test", AccountID: "acc"})
+	if err := c.AddLabels(context.Background(), "repo", 3, []string{"ai-reviewed", "unknown"}); err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	if len(assigned) != 1 || assigned[0] != float64(7) {
+		t.Errorf("assigned = %v, want [7]", assigned)
+	}
+
+	if err := c.RemoveLabels(context.Background(), "repo", 3, []string{"needs-attention"}); err != nil {
+		t.Fatalf("RemoveLabels should ignore unassigned labels: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "/gateway/code/api/v1/repos/repo/pullreq/3/labels/8" {
+		t.Errorf("removed = %v", removed)
+	}
+}
+
+func TestUpdateDescription(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var update map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gateway/code/api/v1/repos/repo/pullreq/3" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"number": 3, "title": "Add feature", "description": "old"}`))
+		case http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test", AccountID: "acc"})
+	if err := c.UpdateDescription(context.Background(), "repo", 3, "new"); err != nil {
+		t.Fatalf("UpdateDescription failed: %v", err)
+	}
+	if update["title"] != "Add feature" || update["description"] != "new" {
+		t.Errorf("unexpected update %v", update)
+	}
+}
+
+func TestRequestReviewers(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var added []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gateway/code/api/v1/principals":
+			if r.URL.Query().Get("accountIdentifier") != "acc" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("query") == "dev@example.com" {
+				_, _ = w.Write([]byte(`[{"id": 21, "uid": "dev", "email": "Dev@example.com"}]`))
+			} else {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/gateway/code/api/v1/repos/repo/pullreq/3/reviewers":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			added = append(added, body["reviewer_id"])
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test", AccountID: "acc"})
+	if err := c.RequestReviewers(context.Background(), "repo", 3, []string{"@dev@example.com", "unknown"}); err != nil {
+		t.Fatalf("RequestReviewers failed: %v", err)
+	}
+	if len(added) != 1 || added[0] != float64(21) {
+		t.Errorf("added = %v, want [21]", added)
+	}
+}
+
+func TestSubmitReview(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var requests []string
+	var review map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/gateway/code/api/v1/repos/repo/pullreq/3":
+			_, _ = w.Write([]byte(`{"number": 3, "source_sha": "head"}`))
+		case "/gateway/code/api/v1/repos/repo/pullreq/3/reviews":
+			_ = json.NewDecoder(r.Body).Decode(&review)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	if err := c.SubmitReview(context.Background(), "repo", 3, "", "changereq", "Please fix"); err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+
+	want := []string{
+		"GET /gateway/code/api/v1/repos/repo/pullreq/3",
+		"POST /gateway/code/api/v1/repos/repo/pullreq/3/comments",
+		"POST /gateway/code/api/v1/repos/repo/pullreq/3/reviews",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	if review["commit_sha"] != "head" || review["decision"] != "changereq" {
+		t.Errorf("unexpected review %v", review)
+	}
+}
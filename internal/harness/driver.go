@@ -0,0 +1,250 @@
+package harness
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+)
+
+// NewSCMClient returns a go-scm client backed by the Harness Code client, so
+// pull requests, reviews and statuses go through the same service interfaces
+// as every other provider. Services Harness Code is not used for return
+// scm.ErrNotSupported.
+func NewSCMClient(c *Client) (*scm.Client, error) {
+	base, err := url.Parse(c.baseURL + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	client := new(scm.Client)
+	client.BaseURL = base
+	client.Driver = scm.DriverHarness
+	client.PullRequests = &pullService{c}
+	client.Reviews = &reviewService{c}
+	client.Repositories = &repositoryService{c}
+	client.Linker = linker{}
+	client.Contents = contentService{}
+	client.Git = gitService{}
+	client.Organizations = organizationService{}
+	client.Issues = issueService{}
+	client.Milestones = milestoneService{}
+	client.Releases = releaseService{}
+	client.Users = userService{}
+	client.Webhooks = webhookService{}
+	return client, nil
+}
+
+// FromSCM returns the Harness Code client behind a client created by
+// NewSCMClient, for Harness-only endpoints such as checks and review
+// decisions. It returns nil for other clients.
+func FromSCM(client *scm.Client) *Client {
+	if client == nil {
+		return nil
+	}
+	if s, ok := client.PullRequests.(*pullService); ok {
+		return s.client
+	}
+	return nil
+}
+
+type pullService struct {
+	client *Client
+}
+
+func (s *pullService) Find(ctx context.Context, repo string, number int) (*scm.PullRequest, *scm.Response, error) {
+	pr, err := s.client.getPR(ctx, repo, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertPullRequest(pr), nil, nil
+}
+
+func (s *pullService) CreateComment(ctx context.Context, repo string, number int, input *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	id, err := s.client.CreateComment(ctx, repo, number, input.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &scm.Comment{ID: id, Body: input.Body}, nil, nil
+}
+
+func (s *pullService) FindComment(context.Context, string, int, int) (*scm.Comment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+// List lists pull requests. The next page is assumed to exist when a page
+// is full, as Harness Code reports no page links.
+func (s *pullService) List(ctx context.Context, repo string, opts scm.PullRequestListOptions) ([]*scm.PullRequest, *scm.Response, error) {
+	prs, err := s.client.listPRs(ctx, repo, listState(opts), opts.Page, opts.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]*scm.PullRequest, 0, len(prs))
+	for i := range prs {
+		out = append(out, convertPullRequest(&prs[i]))
+	}
+
+	res := new(scm.Response)
+	if opts.Size > 0 && len(prs) == opts.Size {
+		res.Page.Next = max(opts.Page, 1) + 1
+	}
+	return out, res, nil
+}
+
+func (s *pullService) ListChanges(context.Context, string, int, scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) ListComments(context.Context, string, int, scm.ListOptions) ([]*scm.Comment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) ListCommits(context.Context, string, int, scm.ListOptions) ([]*scm.Commit, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) Merge(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) Close(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (s *pullService) Create(context.Context, string, *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *pullService) DeleteComment(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+type reviewService struct {
+	client *Client
+}
+
+// Create posts a single-line code comment. Ranges are posted with
+// Client.CreateReviewComment, as scm.ReviewInput has no start line.
+func (s *reviewService) Create(ctx context.Context, repo string, number int, input *scm.ReviewInput) (*scm.Review, *scm.Response, error) {
+	id, err := s.client.CreateInlineComment(ctx, repo, number, input.Path, input.Line, input.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &scm.Review{ID: id, Body: input.Body, Path: input.Path, Line: input.Line, Sha: input.Sha}, nil, nil
+}
+
+func (s *reviewService) Find(context.Context, string, int, int) (*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) List(context.Context, string, int, scm.ListOptions) ([]*scm.Review, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *reviewService) Delete(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+type repositoryService struct {
+	client *Client
+}
+
+// CreateStatus reports a check without a detailed report; use
+// Client.CreateCheck for reports and timing
+func (s *repositoryService) CreateStatus(ctx context.Context, repo, ref string, input *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	err := s.client.CreateStatus(ctx, repo, ref, convertState(input.State), input.Label, input.Desc, input.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &scm.Status{State: input.State, Label: input.Label, Desc: input.Desc, Target: input.Target}, nil, nil
+}
+
+func (s *repositoryService) Find(context.Context, string) (*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) FindHook(context.Context, string, string) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) FindPerms(context.Context, string) (*scm.Perm, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) List(context.Context, scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListV2(context.Context, scm.RepoListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListNamespace(context.Context, string, scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListHooks(context.Context, string, scm.ListOptions) ([]*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListStatus(context.Context, string, string, scm.ListOptions) ([]*scm.Status, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) CreateHook(context.Context, string, *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) UpdateHook(context.Context, string, string, *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) DeleteHook(context.Context, string, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func convertPullRequest(pr *prInfo) *scm.PullRequest {
+	return &scm.PullRequest{
+		Number: pr.Number,
+		Title:  pr.Title,
+		Body:   pr.Description,
+		Sha:    pr.SourceSHA,
+		Source: pr.SourceBranch,
+		Target: pr.TargetBranch,
+		Closed: pr.State != "open",
+		Merged: pr.Merged != nil,
+		Head:   scm.Reference{Name: pr.SourceBranch, Sha: pr.SourceSHA},
+		Base:   scm.Reference{Name: pr.TargetBranch, Sha: pr.MergeBaseSHA},
+	}
+}
+
+// listState maps list options to the Harness Code pull request state filter
+func listState(opts scm.PullRequestListOptions) string {
+	switch {
+	case opts.Open && !opts.Closed:
+		return "open"
+	case opts.Closed && !opts.Open:
+		return "closed"
+	default:
+		return ""
+	}
+}
+
+// convertState maps a go-scm state to the Harness Code check status
+func convertState(state scm.State) string {
+	switch state {
+	case scm.StatePending:
+		return "pending"
+	case scm.StateRunning:
+		return "running"
+	case scm.StateSuccess:
+		return "success"
+	case scm.StateFailure:
+		return "failure"
+	case scm.StateError, scm.StateCanceled:
+		return "error"
+	default:
+		return "pending"
+	}
+}
@@ -0,0 +1,153 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSCMClient(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var posted []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gateway/code/api/v1/repos/repo/pullreq/4":
+			_, _ = w.Write([]byte(`{"number": 4, "title": "Fix", "state": "open", "source_branch": "fix", "target_branch": "main", "source_sha": "head", "merge_base_sha": "base"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/gateway/code/api/v1/repos/repo/pullreq/4/comments":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted = append(posted, body)
+			_, _ = w.Write([]byte(`{"id": 11}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/gateway/code/api/v1/repos/repo/commits/head/checks":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted = append(posted, body)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	client, err := NewSCMClient(c)
+	if err != nil {
+		t.Fatalf("NewSCMClient failed: %v", err)
+	}
+	if FromSCM(client) != c {
+		t.Error("FromSCM should return the backing client")
+	}
+	if FromSCM(new(scm.Client)) != nil {
+		t.Error("FromSCM should return nil for other clients")
+	}
+
+	ctx := context.Background()
+
+	pr, _, err := client.PullRequests.Find(ctx, "repo", 4)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if pr.Sha != "head" || pr.Base.Sha != "base" || pr.Source != "fix" || pr.Closed {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+
+	comment, _, err := client.PullRequests.CreateComment(ctx, "repo", 4, &scm.CommentInput{Body: "hello"})
+	if err != nil || comment.ID != 11 {
+		t.Fatalf("CreateComment = %+v, %v", comment, err)
+	}
+
+	_, _, err = client.Reviews.Create(ctx, "repo", 4, &scm.ReviewInput{Path: "main.go", Line: 7, Body: "nit"})
+	if err != nil {
+		t.Fatalf("Reviews.Create failed: %v", err)
+	}
+
+	_, _, err = client.Repositories.CreateStatus(ctx, "repo", "head", &scm.StatusInput{State: scm.StateFailure, Label: "lint"})
+	if err != nil {
+		t.Fatalf("CreateStatus failed: %v", err)
+	}
+
+	if len(posted) != 3 {
+		t.Fatalf("got %d writes, want 3", len(posted))
+	}
+	if posted[0]["text"] != "hello" {
+		t.Errorf("unexpected comment %v", posted[0])
+	}
+	if posted[1]["path"] != "main.go" || posted[1]["line_start"] != float64(7) || posted[1]["source_commit_sha"] != "head" || posted[1]["target_commit_sha"] != "base" {
+		t.Errorf("unexpected code comment %v", posted[1])
+	}
+	if posted[2]["identifier"] != "lint" || posted[2]["status"] != "failure" {
+		t.Errorf("unexpected check %v", posted[2])
+	}
+
+	if _, _, err := client.Reviews.List(ctx, "repo", 4, scm.ListOptions{}); !errors.Is(err, scm.ErrNotSupported) {
+		t.Errorf("Reviews.List error = %v, want ErrNotSupported", err)
+	}
+
+	// Services Harness Code does not back are stubbed rather than nil
+	unsupported := map[string]error{}
+	_, _, unsupported["Issues"] = client.Issues.CreateComment(ctx, "repo", 4, &scm.CommentInput{Body: "hello"})
+	_, _, unsupported["Contents"] = client.Contents.Find(ctx, "repo", "CODEOWNERS", "main")
+	_, _, unsupported["Git"] = client.Git.FindCommit(ctx, "repo", "head")
+	_, _, unsupported["Users"] = client.Users.Find(ctx)
+	_, _, unsupported["Organizations"] = client.Organizations.Find(ctx, "org")
+	_, _, unsupported["Milestones"] = client.Milestones.Find(ctx, "repo", 1)
+	_, _, unsupported["Releases"] = client.Releases.Find(ctx, "repo", 1)
+	_, unsupported["Linker"] = client.Linker.Resource(ctx, "repo", scm.Reference{})
+	_, unsupported["Webhooks"] = client.Webhooks.Parse(nil, nil)
+	for service, err := range unsupported {
+		if !errors.Is(err, scm.ErrNotSupported) {
+			t.Errorf("%s error = %v, want ErrNotSupported", service, err)
+		}
+	}
+}
+
+func TestSCMClientListPullRequests(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gateway/code/api/v1/repos/repo/pullreq" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("state") != "open" || query.Get("limit") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if query.Get("page") == "1" {
+			_, _ = w.Write([]byte(`[{"number": 1, "source_branch": "a", "source_sha": "sha-a"}, {"number": 2, "source_branch": "b", "source_sha": "sha-b"}]`))
+		} else {
+			_, _ = w.Write([]byte(`[{"number": 3, "source_branch": "c", "source_sha": "sha-c"}]`))
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(Config{Endpoint: srv.URL, Token: "test"})
+	client, err := NewSCMClient(c)
+	if err != nil {
+		t.Fatalf("NewSCMClient failed: %v", err)
+	}
+
+	opts := scm.PullRequestListOptions{Page: 1, Size: 2, Open: true}
+	prs, res, err := client.PullRequests.List(context.Background(), "repo", opts)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prs) != 2 || prs[1].Number != 2 || prs[1].Source != "b" || prs[1].Sha != "sha-b" || res.Page.Next != 2 {
+		t.Errorf("unexpected first page %+v, next %d", prs, res.Page.Next)
+	}
+
+	opts.Page = res.Page.Next
+	prs, res, err = client.PullRequests.List(context.Background(), "repo", opts)
+	if err != nil || len(prs) != 1 || res.Page.Next != 0 {
+		t.Errorf("unexpected last page %+v, %+v, %v", prs, res, err)
+	}
+}
@@ -0,0 +1,222 @@
+package harness
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/drone/go-scm/scm"
+)
+
+// The go-scm services below are not backed by Harness Code. Every call
+// returns scm.ErrNotSupported, so a caller gets an error instead of a nil
+// pointer dereference.
+
+type linker struct{}
+
+func (linker) Resource(context.Context, string, scm.Reference) (string, error) {
+	return "", scm.ErrNotSupported
+}
+
+func (linker) Diff(context.Context, string, scm.Reference, scm.Reference) (string, error) {
+	return "", scm.ErrNotSupported
+}
+
+type contentService struct{}
+
+func (contentService) Find(context.Context, string, string, string) (*scm.Content, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (contentService) Create(context.Context, string, string, *scm.ContentParams) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (contentService) Update(context.Context, string, string, *scm.ContentParams) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (contentService) Delete(context.Context, string, string, *scm.ContentParams) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (contentService) List(context.Context, string, string, string, scm.ListOptions) ([]*scm.ContentInfo, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+type gitService struct{}
+
+func (gitService) CreateBranch(context.Context, string, *scm.ReferenceInput) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (gitService) FindBranch(context.Context, string, string) (*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) FindCommit(context.Context, string, string) (*scm.Commit, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) FindTag(context.Context, string, string) (*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) ListBranches(context.Context, string, scm.ListOptions) ([]*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) ListBranchesV2(context.Context, string, scm.BranchListOptions) ([]*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) ListCommits(context.Context, string, scm.CommitListOptions) ([]*scm.Commit, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) ListChanges(context.Context, string, string, scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) ListTags(context.Context, string, scm.ListOptions) ([]*scm.Reference, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (gitService) CompareChanges(context.Context, string, string, string, scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+type organizationService struct{}
+
+func (organizationService) Find(context.Context, string) (*scm.Organization, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (organizationService) FindMembership(context.Context, string, string) (*scm.Membership, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (organizationService) List(context.Context, scm.ListOptions) ([]*scm.Organization, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+type issueService struct{}
+
+func (issueService) Find(context.Context, string, int) (*scm.Issue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) FindComment(context.Context, string, int, int) (*scm.Comment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) List(context.Context, string, scm.IssueListOptions) ([]*scm.Issue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) ListComments(context.Context, string, int, scm.ListOptions) ([]*scm.Comment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) Create(context.Context, string, *scm.IssueInput) (*scm.Issue, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) CreateComment(context.Context, string, int, *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (issueService) DeleteComment(context.Context, string, int, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (issueService) Close(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (issueService) Lock(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (issueService) Unlock(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+type milestoneService struct{}
+
+func (milestoneService) Find(context.Context, string, int) (*scm.Milestone, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (milestoneService) List(context.Context, string, scm.MilestoneListOptions) ([]*scm.Milestone, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (milestoneService) Create(context.Context, string, *scm.MilestoneInput) (*scm.Milestone, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (milestoneService) Update(context.Context, string, int, *scm.MilestoneInput) (*scm.Milestone, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (milestoneService) Delete(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+type releaseService struct{}
+
+func (releaseService) Find(context.Context, string, int) (*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) FindByTag(context.Context, string, string) (*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) List(context.Context, string, scm.ReleaseListOptions) ([]*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) Create(context.Context, string, *scm.ReleaseInput) (*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) Update(context.Context, string, int, *scm.ReleaseInput) (*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) UpdateByTag(context.Context, string, string, *scm.ReleaseInput) (*scm.Release, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (releaseService) Delete(context.Context, string, int) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+func (releaseService) DeleteByTag(context.Context, string, string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+type userService struct{}
+
+func (userService) Find(context.Context) (*scm.User, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (userService) FindEmail(context.Context) (string, *scm.Response, error) {
+	return "", nil, scm.ErrNotSupported
+}
+
+func (userService) FindLogin(context.Context, string) (*scm.User, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (userService) ListEmail(context.Context, scm.ListOptions) ([]*scm.Email, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+type webhookService struct{}
+
+func (webhookService) Parse(*http.Request, scm.SecretFunc) (scm.Webhook, error) {
+	return nil, scm.ErrNotSupported
+}
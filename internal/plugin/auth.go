@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// githubApp returns the GitHub App installation to authenticate as, or nil
+// when GITHUB_APP_ID is not set. The private key is read from
+// GITHUB_PRIVATE_KEY or, failing that, GITHUB_PRIVATE_KEY_FILE.
+func githubApp(cfg Config) (*scmclient.GitHubApp, error) {
+	if cfg.GitHubAppID == 0 {
+		return nil, nil
+	}
+	if cfg.GitHubInstallationID == 0 {
+		return nil, fmt.Errorf("GITHUB_INSTALLATION_ID is required with GITHUB_APP_ID")
+	}
+
+	key := []byte(cfg.GitHubPrivateKey)
+	if len(key) == 0 {
+		if cfg.GitHubPrivateKeyFile == "" {
+			return nil, fmt.Errorf("GITHUB_PRIVATE_KEY or GITHUB_PRIVATE_KEY_FILE is required with GITHUB_APP_ID")
+		}
+		data, err := os.ReadFile(cfg.GitHubPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+		key = data
+	}
+
+	return &scmclient.GitHubApp{
+		AppID:          cfg.GitHubAppID,
+		InstallationID: cfg.GitHubInstallationID,
+		PrivateKey:     key,
+	}, nil
+}
+
+// oauth2Config returns the OAuth2 flow that renews access tokens, or nil when
+// OAUTH_GRANT_TYPE is not set
+func oauth2Config(cfg Config) (*scmclient.OAuth2, error) {
+	grant := strings.ToLower(strings.TrimSpace(cfg.OAuthGrantType))
+	switch grant {
+	case "":
+		return nil, nil
+	case scmclient.GrantRefreshToken:
+		if cfg.OAuthRefreshToken == "" {
+			return nil, fmt.Errorf("OAUTH_REFRESH_TOKEN is required for the refresh_token grant")
+		}
+	case scmclient.GrantClientCredentials:
+		if cfg.OAuthClientID == "" || cfg.OAuthClientSecret == "" {
+			return nil, fmt.Errorf("OAUTH_CLIENT_ID and OAUTH_CLIENT_SECRET are required for the client_credentials grant")
+		}
+	default:
+		return nil, fmt.Errorf("invalid OAUTH_GRANT_TYPE %q (expected refresh_token or client_credentials)", cfg.OAuthGrantType)
+	}
+
+	return &scmclient.OAuth2{
+		GrantType:    grant,
+		ClientID:     cfg.OAuthClientID,
+		ClientSecret: cfg.OAuthClientSecret,
+		RefreshToken: cfg.OAuthRefreshToken,
+		TokenURL:     cfg.OAuthTokenURL,
+		Scopes:       cfg.OAuthScopes,
+	}, nil
+}
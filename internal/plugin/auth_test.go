@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitHubApp(t *testing.T) {
+	if app, err := githubApp(Config{}); app != nil || err != nil {
+		t.Errorf("githubApp without app ID = %v, %v", app, err)
+	}
+
+	if _, err := githubApp(Config{GitHubAppID: 1, GitHubPrivateKey: "key"}); err == nil {
+		t.Error("githubApp should require an installation ID")
+	}
+	if _, err := githubApp(Config{GitHubAppID: 1, GitHubInstallationID: 2}); err == nil {
+		t.Error("githubApp should require a private key")
+	}
+
+	file := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(file, []byte("pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app, err := githubApp(Config{GitHubAppID: 1, GitHubInstallationID: 2, GitHubPrivateKeyFile: file})
+	if err != nil {
+		t.Fatalf("githubApp failed: %v", err)
+	}
+	if string(app.PrivateKey) != "pem" || app.AppID != 1 || app.InstallationID != 2 {
+		t.Errorf("unexpected app %+v", app)
+	}
+}
+
+func TestNewRequiresCredentials(t *testing.T) {
+	if _, err := New(Config{SCMProvider: "github", Repo: "owner/repo"}); err == nil {
+		t.Error("New should fail without TOKEN or a GitHub App")
+	}
+}
+
+func TestOAuth2Config(t *testing.T) {
+	if oauth, err := oauth2Config(Config{}); oauth != nil || err != nil {
+		t.Errorf("oauth2Config without grant = %v, %v", oauth, err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"refresh token", Config{OAuthGrantType: "Refresh_Token", OAuthRefreshToken: "r"}, false},
+		{"missing refresh token", Config{OAuthGrantType: "refresh_token"}, true},
+		{"client credentials", Config{OAuthGrantType: "client_credentials", OAuthClientID: "id", OAuthClientSecret: "s"}, false},
+		{"missing secret", Config{OAuthGrantType: "client_credentials", OAuthClientID: "id"}, true},
+		{"unknown grant", Config{OAuthGrantType: "password"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauth, err := oauth2Config(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("oauth2Config error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && oauth.GrantType != strings.ToLower(tt.cfg.OAuthGrantType) {
+				t.Errorf("GrantType = %q", oauth.GrantType)
+			}
+		})
+	}
+
+	// An OAuth2 grant replaces TOKEN
+	cfg := Config{SCMProvider: "gitlab", Repo: "group/project", OAuthGrantType: "refresh_token", OAuthRefreshToken: "r"}
+	if _, err := New(cfg); err != nil {
+		t.Errorf("New with an OAuth2 grant failed: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// supports reports whether the provider supports the capability
+func (p *Plugin) supports(capability scmclient.Capability) bool {
+	return p.provider.Capabilities().Has(capability)
+}
+
+// degraded logs that a requested feature is posted in a simpler form because
+// the provider does not support it
+func (p *Plugin) degraded(capability scmclient.Capability, fallback string, fields logrus.Fields) {
+	p.log.WithFields(fields).WithFields(logrus.Fields{
+		"scm_provider": p.provider,
+		"capability":   capability,
+		"fallback":     fallback,
+	}).Warn("provider does not support this feature, degrading")
+}
+
+// logConfigDegradations reports settings the provider cannot honor, before
+// anything is posted
+func (p *Plugin) logConfigDegradations() {
+	if p.config.Checks && !p.supports(scmclient.CapabilityChecks) {
+		p.degraded(scmclient.CapabilityChecks, "commit statuses and comments", nil)
+	}
+	if event, _ := reviewEvent(p.config.ReviewEvent); event != reviewEventComment && !p.supports(scmclient.CapabilityReviewState) {
+		p.degraded(scmclient.CapabilityReviewState, "comments without a review state", logrus.Fields{"review_event": event})
+	}
+	if p.hasLabels() && !p.supports(scmclient.CapabilityLabels) {
+		p.degraded(scmclient.CapabilityLabels, "labels left unchanged", nil)
+	}
+	if p.config.CodeownersReviewers && !p.supports(scmclient.CapabilityReviewers) {
+		p.degraded(scmclient.CapabilityReviewers, "no reviewers requested", nil)
+	}
+	if p.config.DescriptionSummary && !p.supports(scmclient.CapabilityDescription) {
+		p.degraded(scmclient.CapabilityDescription, "description left unchanged", nil)
+	}
+	if p.config.AzureResolveFixed && !p.supports(scmclient.CapabilityResolve) {
+		p.degraded(scmclient.CapabilityResolve, "threads left open", nil)
+	}
+}
+
+// logReviewDegradations reports findings that lose precision on the provider:
+// line ranges anchored to their last line and suggestions shown as plain code
+func (p *Plugin) logReviewDegradations(reviews []ReviewComment) {
+	var ranges, suggestions int
+	for _, review := range reviews {
+		if review.LineNumberEnd > review.LineNumberStart {
+			ranges++
+		}
+		if review.Suggestion != "" {
+			suggestions++
+		}
+	}
+
+	if ranges > 0 && !p.supports(scmclient.CapabilityRanges) {
+		p.degraded(scmclient.CapabilityRanges, "single line at line_number_end", logrus.Fields{"count": ranges})
+	}
+	if suggestions > 0 && !p.supports(scmclient.CapabilitySuggestions) {
+		p.degraded(scmclient.CapabilitySuggestions, "fenced code block", logrus.Fields{"count": suggestions})
+	}
+}
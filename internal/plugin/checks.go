@@ -0,0 +1,330 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/abhinav-harness/comment-plugin/internal/github"
+	"github.com/abhinav-harness/comment-plugin/internal/harness"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultCheckName = "comment-plugin"
+
+// GitHub check run limits: the title, summary and text are counted in
+// characters, annotation messages in bytes
+const (
+	maxCheckSummary      = 65535
+	maxAnnotationTitle   = 255
+	maxAnnotationMessage = 64 * 1024
+)
+
+func (p *Plugin) checkName() string {
+	if p.config.StatusContext != "" {
+		return p.config.StatusContext
+	}
+	return defaultCheckName
+}
+
+// headSHA returns COMMIT_SHA, falling back to the head of the pull request
+func (p *Plugin) headSHA(ctx context.Context) (string, error) {
+	if p.config.CommitSHA != "" {
+		return p.config.CommitSHA, nil
+	}
+	if p.config.PRNumber == 0 {
+		return "", fmt.Errorf("COMMIT_SHA or PR_NUMBER is required")
+	}
+
+	pr, err := p.getPRDetails(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR details: %w", err)
+	}
+	return pr.SourceSHA, nil
+}
+
+// createCheckRunFromReviews publishes the batch as a single GitHub check run
+// with one annotation per finding.
+func (p *Plugin) createCheckRunFromReviews(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	sha, err := p.headSHA(ctx)
+	if err != nil {
+		return err
+	}
+
+	annotations := make([]github.Annotation, 0, len(reviews))
+	for _, review := range reviews {
+		annotations = append(annotations, github.Annotation{
+			Path:        review.FilePath,
+			StartLine:   review.LineNumberStart,
+			EndLine:     review.LineNumberEnd,
+			StartColumn: review.StartColumn,
+			EndColumn:   review.EndColumn,
+			Level:       annotationLevel(severityOf(review)),
+			Title:       truncate(annotationTitle(review), maxAnnotationTitle),
+			Message:     truncateBytes(annotationMessage(review, opts), maxAnnotationMessage),
+		})
+	}
+
+	_, err = p.github.CreateCheckRun(ctx, p.config.Repo, github.CheckRunInput{
+		Name:        p.checkName(),
+		HeadSHA:     sha,
+		Status:      "completed",
+		Conclusion:  checkConclusion(reviews),
+		DetailsURL:  p.config.StatusURL,
+		Title:       summaryTitle(reviews),
+		Summary:     truncate(summaryMarkdown(reviews), maxCheckSummary),
+		Annotations: annotations,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"check": p.checkName(),
+		"count": len(reviews),
+	}).Info("created check run from reviews")
+	return nil
+}
+
+// createCheckRunStatus publishes STATUS_STATE as a GitHub check run
+func (p *Plugin) createCheckRunStatus(ctx context.Context) error {
+	status, conclusion := checkStatus(p.config.StatusState, p.config.StatusURL)
+
+	_, err := p.github.CreateCheckRun(ctx, p.config.Repo, github.CheckRunInput{
+		Name:       p.checkName(),
+		HeadSHA:    p.config.CommitSHA,
+		Status:     status,
+		Conclusion: conclusion,
+		DetailsURL: p.config.StatusURL,
+		Title:      p.config.StatusDesc,
+		Summary:    truncate(p.config.StatusDesc, maxCheckSummary),
+	})
+	if err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{"state": p.config.StatusState, "check": p.checkName()}).Info("created check run")
+	return nil
+}
+
+// reportChecker publishes commit checks that carry a report and their timing,
+// as Harness Code does
+type reportChecker interface {
+	CreateCheck(ctx context.Context, repo, commitSHA string, input harness.CheckInput) error
+	CheckStarted(ctx context.Context, repo, commitSHA, identifier string) (time.Time, error)
+}
+
+// reportChecker returns the provider's client when its checks carry a
+// report, nil otherwise
+func (p *Plugin) reportChecker() reportChecker {
+	checker, _ := p.native.(reportChecker)
+	return checker
+}
+
+// createReportStatus publishes STATUS_STATE as a check with the optional
+// STATUS_DETAILS report. A running check records its start time and a
+// finished one its end time, so reporting running first and the result later
+// shows how long the step took.
+func (p *Plugin) createReportStatus(ctx context.Context) error {
+	started, ended, err := p.reportCheckTimes(ctx, p.config.CommitSHA, p.config.StatusState)
+	if err != nil {
+		return err
+	}
+
+	return p.reportChecker().CreateCheck(ctx, p.config.Repo, p.config.CommitSHA, harness.CheckInput{
+		Identifier:  p.checkName(),
+		State:       p.config.StatusState,
+		Summary:     p.config.StatusDesc,
+		Link:        p.config.StatusURL,
+		PayloadKind: p.config.StatusPayloadKind,
+		Details:     p.config.StatusDetails,
+		Started:     started,
+		Ended:       ended,
+	})
+}
+
+// createReportCheckFromReviews publishes the batch as a finished check whose
+// report is the markdown summary of every finding
+func (p *Plugin) createReportCheckFromReviews(ctx context.Context, reviews []ReviewComment) error {
+	sha, err := p.headSHA(ctx)
+	if err != nil {
+		return err
+	}
+
+	state := "success"
+	if checkConclusion(reviews) == "failure" {
+		state = "failure"
+	}
+
+	started, ended, err := p.reportCheckTimes(ctx, sha, state)
+	if err != nil {
+		return err
+	}
+
+	err = p.reportChecker().CreateCheck(ctx, p.config.Repo, sha, harness.CheckInput{
+		Identifier:  p.checkName(),
+		State:       state,
+		Summary:     summaryTitle(reviews),
+		Link:        p.config.StatusURL,
+		PayloadKind: "markdown",
+		Details:     summaryMarkdown(reviews),
+		Started:     started,
+		Ended:       ended,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"check": p.checkName(),
+		"count": len(reviews),
+	}).Info("created check from reviews")
+	return nil
+}
+
+// reportCheckTimes returns the start and end time of a report check. Each
+// report replaces the whole check, so a finished check without
+// STATUS_STARTED keeps the start time of an earlier running report; when
+// there is none, the start time is left out.
+func (p *Plugin) reportCheckTimes(ctx context.Context, sha, state string) (time.Time, time.Time, error) {
+	started, ended, err := checkTimes(state, p.config.StatusStarted, time.Now())
+	if err != nil || !started.IsZero() || ended.IsZero() {
+		return started, ended, err
+	}
+
+	started, err = p.reportChecker().CheckStarted(ctx, p.config.Repo, sha, p.checkName())
+	if err != nil {
+		p.log.WithError(err).Warn("failed to read the check's start time, leaving it out")
+		return time.Time{}, ended, nil
+	}
+	return started, ended, nil
+}
+
+// validPayloadKind reports whether STATUS_PAYLOAD_KIND is supported
+func validPayloadKind(kind string) bool {
+	switch kind {
+	case "", "markdown", "raw":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkTimes returns the start and end time to report for a check state. A
+// running check starts now; a finished check ends now and starts at
+// STATUS_STARTED when given.
+func checkTimes(state, startedAt string, now time.Time) (time.Time, time.Time, error) {
+	switch strings.ToLower(state) {
+	case "running":
+		return now, time.Time{}, nil
+	case "success", "failure", "failed", "error":
+		started, err := parseTimestamp(startedAt)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return started, now, nil
+	default:
+		return time.Time{}, time.Time{}, nil
+	}
+}
+
+// parseTimestamp parses unix seconds or an RFC 3339 time; empty is zero
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid STATUS_STARTED %q, expected unix seconds or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// checkStatus maps a STATUS_STATE value to a check run status and conclusion.
+// An error asks for action only with a details URL, which GitHub requires for
+// action_required; otherwise it fails the check.
+func checkStatus(state, detailsURL string) (string, string) {
+	switch strings.ToLower(state) {
+	case "success":
+		return "completed", "success"
+	case "failure", "failed":
+		return "completed", "failure"
+	case "error":
+		if detailsURL == "" {
+			return "completed", "failure"
+		}
+		return "completed", "action_required"
+	case "running":
+		return "in_progress", ""
+	default:
+		return "queued", ""
+	}
+}
+
+// checkConclusion derives the check conclusion from the most severe finding:
+// critical or high fails the check, medium is neutral, anything else passes.
+func checkConclusion(reviews []ReviewComment) string {
+	switch highestSeverity(reviews) {
+	case SeverityCritical, SeverityHigh:
+		return "failure"
+	case SeverityMedium:
+		return "neutral"
+	default:
+		return "success"
+	}
+}
+
+func annotationLevel(severity string) string {
+	switch severity {
+	case SeverityCritical, SeverityHigh:
+		return "failure"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+func annotationTitle(review ReviewComment) string {
+	var parts []string
+	if review.Type != "" {
+		parts = append(parts, review.Type)
+	}
+	if review.RuleID != "" {
+		parts = append(parts, review.RuleID)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// truncateBytes shortens text to at most limit bytes without splitting a
+// character, marking the cut
+func truncateBytes(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit - len("…")
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…"
+}
+
+// annotationMessage is the plain-text message of an annotation; annotations
+// do not render markdown, so only the text and source are included.
+func annotationMessage(review ReviewComment, opts renderOptions) string {
+	message := review.Review
+	if review.DocumentationURL != "" {
+		message += "\n\nDocumentation: " + review.DocumentationURL
+	}
+	if opts.Attribute && review.Source != "" {
+		message += "\n\nSource: " + review.Source
+	}
+	return message
+}
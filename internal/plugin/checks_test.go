@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCheckConclusion(t *testing.T) {
+	tests := []struct {
+		severities []string
+		want       string
+	}{
+		{nil, "success"},
+		{[]string{"low", "info"}, "success"},
+		{[]string{"low", ""}, "neutral"}, // unspecified severity is medium
+		{[]string{"medium", "high"}, "failure"},
+		{[]string{"critical"}, "failure"},
+	}
+
+	for _, tt := range tests {
+		var reviews []ReviewComment
+		for _, severity := range tt.severities {
+			reviews = append(reviews, ReviewComment{Severity: severity})
+		}
+		if got := checkConclusion(reviews); got != tt.want {
+			t.Errorf("checkConclusion(%v) = %q, want %q", tt.severities, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	tests := map[string][2]string{
+		"success": {"completed", "success"},
+		"failure": {"completed", "failure"},
+		"error":   {"completed", "action_required"},
+		"running": {"in_progress", ""},
+		"pending": {"queued", ""},
+	}
+
+	for state, want := range tests {
+		status, conclusion := checkStatus(state, "https://ci.example.com/build/1")
+		if status != want[0] || conclusion != want[1] {
+			t.Errorf("checkStatus(%q) = %q, %q, want %q, %q", state, status, conclusion, want[0], want[1])
+		}
+	}
+
+	// action_required is rejected without a details URL
+	if _, conclusion := checkStatus("error", ""); conclusion != "failure" {
+		t.Errorf("checkStatus(error) without a URL = %q, want failure", conclusion)
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	if got := truncateBytes("short", 10); got != "short" {
+		t.Errorf("truncateBytes kept %q, want short", got)
+	}
+
+	got := truncateBytes(strings.Repeat("é", 10), 9)
+	if len(got) > 9 || !utf8.ValidString(got) || !strings.HasSuffix(got, "…") {
+		t.Errorf("truncateBytes = %q (%d bytes), want at most 9 valid bytes ending in …", got, len(got))
+	}
+}
+
+func TestSummaryMarkdown(t *testing.T) {
+	reviews := []ReviewComment{
+		{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Type: "bug", Review: "first | line\nmore", Severity: "high"},
+		{FilePath: "b.go", LineNumberStart: 2, LineNumberEnd: 4, Type: "style", Review: "second"},
+	}
+
+	got := summaryMarkdown(reviews)
+	for _, want := range []string{
+		"**2 findings:** 🟠 1 high · 🟡 1 medium",
+		"| 🟠 high | `a.go:1` | bug | first \\| line |",
+		"| 🟡 medium | `b.go:2-4` | style | second |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summaryMarkdown missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := summaryMarkdown(nil); got != "✅ No findings." {
+		t.Errorf("summaryMarkdown(nil) = %q", got)
+	}
+}
+
+func TestCheckTimes(t *testing.T) {
+	now := time.Unix(2000, 0)
+
+	started, ended, err := checkTimes("running", "", now)
+	if err != nil || !started.Equal(now) || !ended.IsZero() {
+		t.Errorf("running check = %v, %v, %v", started, ended, err)
+	}
+
+	started, ended, err = checkTimes("success", "1000", now)
+	if err != nil || !started.Equal(time.Unix(1000, 0)) || !ended.Equal(now) {
+		t.Errorf("finished check = %v, %v, %v", started, ended, err)
+	}
+
+	started, _, err = checkTimes("failure", "1970-01-01T00:10:00Z", now)
+	if err != nil || !started.Equal(time.Unix(600, 0)) {
+		t.Errorf("RFC 3339 start = %v, %v", started, err)
+	}
+
+	started, ended, err = checkTimes("pending", "1000", now)
+	if err != nil || !started.IsZero() || !ended.IsZero() {
+		t.Errorf("pending check should have no times: %v, %v, %v", started, ended, err)
+	}
+
+	if _, _, err := checkTimes("success", "yesterday", now); err == nil {
+		t.Error("checkTimes should reject an invalid STATUS_STARTED")
+	}
+}
+
+func TestHarnessCheckKeepsStartTime(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	tests := []struct {
+		name    string
+		checks  string
+		started interface{}
+	}{
+		{"running report", `[{"identifier": "lint", "started": 5000}, {"identifier": "ai-review", "started": 1700000000000}]`, float64(1700000000000)},
+		{"no running report", `[]`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/gateway/code/api/v1/repos/repo/commits/abc/checks" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(tt.checks))
+					return
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			p, err := New(Config{
+				SCMProvider:   "harness",
+				SCMEndpoint:   srv.URL,
+				Token:         "token",
+				Repo:          "repo",
+				CommitSHA:     "abc",
+				StatusState:   "success",
+				StatusContext: "ai-review",
+			})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			if err := p.Execute(context.Background()); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if body["started"] != tt.started || body["ended"] == nil {
+				t.Errorf("started = %v, ended = %v, want started %v", body["started"], body["ended"], tt.started)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// codeownersPaths are the locations searched for a CODEOWNERS file when
+// CODEOWNERS_FILE is not set, relative to the repository root
+var codeownersPaths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	".gitea/CODEOWNERS",
+	".harness/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// codeownersRule assigns owners to the files matching a pattern. A rule
+// without owners leaves its files unowned.
+type codeownersRule struct {
+	segments []string
+	owners   []string
+}
+
+// parseCodeowners parses a CODEOWNERS file. GitLab section headers are
+// skipped, so their rules are read like any other.
+func parseCodeowners(r io.Reader) ([]codeownersRule, error) {
+	var rules []codeownersRule
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		segments, err := codeownersSegments(strings.TrimPrefix(fields[0], `\`))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CODEOWNERS pattern %q on line %d: %w", fields[0], lineNumber, err)
+		}
+		rules = append(rules, codeownersRule{segments: segments, owners: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// codeownersSegments turns a gitignore-style pattern into segments for
+// matchSegments. Patterns with a leading or inner slash are anchored to the
+// repository root, others match at any depth. A pattern also matches
+// everything below a matching directory, and a trailing slash matches only
+// directories.
+func codeownersSegments(pattern string) ([]string, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	segments := strings.Split(pattern, "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if !anchored {
+		segments = append([]string{"**"}, segments...)
+	}
+	if dirOnly {
+		segments = append(segments, "*")
+	}
+	return append(segments, "**"), nil
+}
+
+// ownersOf returns the owners of a file: those of the last matching rule
+func ownersOf(rules []codeownersRule, file string) []string {
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	name := strings.Split(file, "/")
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if matchSegments(rules[i].segments, name) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeowners returns the owners of the files with findings at least as
+// severe as minSeverity, de-duplicated in order of appearance
+func codeowners(rules []codeownersRule, reviews []ReviewComment, minSeverity string) []string {
+	var owners []string
+	seen := make(map[string]bool)
+
+	for _, review := range reviews {
+		if review.FilePath == "" || severityRank(severityOf(review)) < severityRank(minSeverity) {
+			continue
+		}
+		for _, owner := range ownersOf(rules, review.FilePath) {
+			if key := strings.ToLower(owner); !seen[key] {
+				seen[key] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// loadCodeowners reads the rules of CODEOWNERS_FILE, or of the first
+// CODEOWNERS file found in the usual locations. It returns an empty path
+// when no file is configured and none is found.
+func loadCodeowners(file string) (string, []codeownersRule, error) {
+	candidates := codeownersPaths
+	if file != "" {
+		candidates = []string{file}
+	}
+
+	for _, candidate := range candidates {
+		f, err := os.Open(candidate)
+		if errors.Is(err, os.ErrNotExist) && file == "" {
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to open CODEOWNERS file: %w", err)
+		}
+		defer f.Close()
+
+		rules, err := parseCodeowners(f)
+		return candidate, rules, err
+	}
+	return "", nil, nil
+}
+
+// requestCodeowners requests reviews from the code owners of the files with
+// findings, when CODEOWNERS_REVIEWERS is set. Reviewers are a courtesy, so a
+// failed request is logged instead of failing the step.
+func (p *Plugin) requestCodeowners(ctx context.Context, reviews []ReviewComment) error {
+	if !p.config.CodeownersReviewers {
+		return nil
+	}
+	if !p.supports(scmclient.CapabilityReviewers) {
+		// Already reported by logConfigDegradations
+		return nil
+	}
+	if p.config.PRNumber == 0 {
+		p.log.Warn("requesting code owners needs PR_NUMBER, skipping")
+		return nil
+	}
+
+	file, rules, err := loadCodeowners(p.config.CodeownersFile)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		p.log.WithField("paths", codeownersPaths).Warn("no CODEOWNERS file found, skipping reviewers")
+		return nil
+	}
+
+	minSeverity := p.config.CodeownersSeverity
+	if minSeverity == "" {
+		minSeverity = SeverityInfo
+	}
+	owners := p.excludeAuthor(ctx, codeowners(rules, reviews, minSeverity))
+	if len(owners) == 0 {
+		p.log.WithField("codeowners_file", file).Info("no code owners for the files with findings")
+		return nil
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"codeowners_file": file,
+		"owners":          owners,
+	}).Info("requesting reviews from code owners")
+
+	if err := p.reviewerClient().RequestReviewers(ctx, p.config.Repo, p.config.PRNumber, owners); err != nil {
+		p.log.WithError(err).Warn("failed to request reviews from code owners")
+	}
+	return nil
+}
+
+// excludeAuthor drops the pull request author from the owners, as providers
+// reject review requests from the author
+func (p *Plugin) excludeAuthor(ctx context.Context, owners []string) []string {
+	if len(owners) == 0 {
+		return owners
+	}
+
+	pr, err := p.pullRequest(ctx)
+	if err != nil {
+		p.log.WithError(err).Debug("failed to get pull request author")
+		return owners
+	}
+	author := pr.Author.Login
+	if author == "" {
+		return owners
+	}
+
+	var out []string
+	for _, owner := range owners {
+		if !strings.EqualFold(strings.TrimPrefix(owner, "@"), author) {
+			out = append(out, owner)
+		}
+	}
+	return out
+}
+
+// reviewerRequester requests pull request reviewers on one provider
+type reviewerRequester interface {
+	RequestReviewers(ctx context.Context, repo string, prNumber int, owners []string) error
+}
+
+// reviewerClient returns the client that requests reviewers for the
+// provider. Only called for providers with the reviewers capability.
+func (p *Plugin) reviewerClient() reviewerRequester {
+	client, _ := p.native.(reviewerRequester)
+	return client
+}
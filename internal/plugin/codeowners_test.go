@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCodeowners = `# Default owners
+*                 @acme/everyone
+
+[Backend]
+*.go              @acme/backend   # Go code
+/internal/auth/   @alice sec@example.com
+docs/**           @writer
+/vendor/
+`
+
+func TestOwnersOf(t *testing.T) {
+	rules, err := parseCodeowners(strings.NewReader(testCodeowners))
+	if err != nil {
+		t.Fatalf("parseCodeowners failed: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"README.md", []string{"@acme/everyone"}},
+		{"cmd/plugin/main.go", []string{"@acme/backend"}},
+		{"./internal/auth/token.go", []string{"@alice", "sec@example.com"}},
+		{"internal/auth", []string{"@acme/everyone"}},
+		{"pkg/internal/auth/token.go", []string{"@acme/backend"}},
+		{"docs/guide/setup.md", []string{"@writer"}},
+		{"vendor/lib/lib.go", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := ownersOf(rules, tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ownersOf(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestParseCodeownersInvalidPattern(t *testing.T) {
+	if _, err := parseCodeowners(strings.NewReader("src/[.go @owner\n")); err == nil {
+		t.Fatal("parseCodeowners should reject an invalid pattern")
+	}
+}
+
+func TestCodeowners(t *testing.T) {
+	rules, err := parseCodeowners(strings.NewReader(testCodeowners))
+	if err != nil {
+		t.Fatalf("parseCodeowners failed: %v", err)
+	}
+
+	reviews := []ReviewComment{
+		{FilePath: "main.go", Severity: "low"},
+		{FilePath: "internal/auth/token.go", Severity: "critical"},
+		{FilePath: "internal/auth/session.go", Severity: "high"},
+		{FilePath: "docs/index.md", Severity: "info"},
+	}
+
+	want := []string{"@acme/backend", "@alice", "sec@example.com", "@writer"}
+	if got := codeowners(rules, reviews, SeverityInfo); !reflect.DeepEqual(got, want) {
+		t.Errorf("codeowners = %v, want %v", got, want)
+	}
+
+	want = []string{"@alice", "sec@example.com"}
+	if got := codeowners(rules, reviews, SeverityHigh); !reflect.DeepEqual(got, want) {
+		t.Errorf("codeowners at high = %v, want %v", got, want)
+	}
+}
+
+func TestLoadCodeowners(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if file, _, err := loadCodeowners(""); file != "" || err != nil {
+		t.Fatalf("loadCodeowners without a file = %q, %v", file, err)
+	}
+	if _, _, err := loadCodeowners("missing/CODEOWNERS"); err == nil {
+		t.Error("a configured CODEOWNERS file must exist")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @owner\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, rules, err := loadCodeowners("")
+	if err != nil || file != ".github/CODEOWNERS" || len(rules) != 1 {
+		t.Errorf("loadCodeowners = %q, %v, %v", file, rules, err)
+	}
+}
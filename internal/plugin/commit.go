@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// commitComments reports whether comments go on COMMIT_SHA: push builds have
+// no PR_NUMBER, and only some providers can comment on commits
+func (p *Plugin) commitComments() bool {
+	return p.config.PRNumber == 0 && p.config.CommitSHA != "" && p.supports(scmclient.CapabilityCommitComments)
+}
+
+// createCommitComment posts COMMENT_BODY on the commit, on FILE_PATH and LINE
+// when they are set
+func (p *Plugin) createCommitComment(ctx context.Context, filePath string, line int) error {
+	err := p.commitClient().CreateCommitComment(ctx, p.config.Repo, p.config.CommitSHA, filePath, line, p.config.CommentBody)
+	if err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"commit_sha": p.config.CommitSHA,
+		"file":       filePath,
+		"line":       line,
+	}).Info("created commit comment")
+	return nil
+}
+
+// createCommitComments posts each review on the commit at line_number_end.
+// Commit comments cannot span lines or apply suggestions.
+func (p *Plugin) createCommitComments(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	opts.Ranges = false
+	opts.Suggestions = suggestionFenced
+
+	for i, review := range reviews {
+		err := p.commitClient().CreateCommitComment(ctx, p.config.Repo, p.config.CommitSHA, review.FilePath, review.LineNumberEnd, formatReview(review, opts))
+		if err != nil {
+			p.log.WithError(err).WithField("index", i).WithField("path", review.FilePath).Warn("failed to create commit comment")
+		}
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"commit_sha": p.config.CommitSHA,
+		"count":      len(reviews),
+	}).Info("finished creating commit comments from file")
+	return nil
+}
+
+// commitCommenter comments on commits on one provider
+type commitCommenter interface {
+	CreateCommitComment(ctx context.Context, repo, commitSHA, filePath string, line int, body string) error
+}
+
+// commitClient returns the client that comments on commits for the provider.
+// Only called for providers with the commit comments capability.
+func (p *Plugin) commitClient() commitCommenter {
+	client, _ := p.native.(commitCommenter)
+	return client
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCommitCommentsFromFile(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var notes []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/group/project/merge_requests" {
+			// No open merge request for the commit
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/repository/commits/abc123/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		notes = append(notes, body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "reviews.json")
+	writeFile(t, file, `{"version":2,"reviews":[{"file_path":"a.go","line_number_start":2,"line_number_end":4,"type":"bug","review":"off by one","suggestion":"i <= n"}]}`)
+
+	p, err := New(Config{
+		SCMProvider:   "gitlab",
+		SCMEndpoint:   srv.URL,
+		Token:         "token",
+		Repo:          "group/project",
+		CommitSHA:     "abc123",
+		CommentsFiles: []string{file},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(notes) != 1 {
+		t.Fatalf("got %d commit comments, want 1", len(notes))
+	}
+	if notes[0]["path"] != "a.go" || notes[0]["line"] != float64(4) {
+		t.Errorf("unexpected anchor %v", notes[0])
+	}
+	if body, _ := notes[0]["note"].(string); !strings.Contains(body, "Suggested change (lines 2-4)") {
+		t.Errorf("suggestion should be a plain code block: %q", body)
+	}
+}
+
+func TestCommentWithoutPRNumber(t *testing.T) {
+	p, err := New(Config{SCMProvider: "gitea", SCMEndpoint: "https://gitea.example.com", Token: "token", Repo: "owner/repo", CommitSHA: "abc123", CommentBody: "hi"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.createComment(context.Background()); err == nil {
+		t.Error("providers without commit comments still need PR_NUMBER")
+	}
+}
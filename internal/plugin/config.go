@@ -3,14 +3,31 @@ package plugin
 // Config holds the plugin configuration parsed from environment variables
 type Config struct {
 	// SCM Provider
-	SCMProvider string `envconfig:"SCM_PROVIDER"` // github, gitlab, bitbucket, gitea, gogs, harness
+	SCMProvider string `envconfig:"SCM_PROVIDER"` // github, gitlab, bitbucket, gitea, gogs, harness, azure-devops, gerrit
 	SCMEndpoint string `envconfig:"SCM_ENDPOINT"` // Custom endpoint for self-hosted
-	Token       string `envconfig:"TOKEN" required:"true"`
+	Token       string `envconfig:"TOKEN"`        // Required unless a GitHub App or OAuth2 grant is configured
+	Username    string `envconfig:"USERNAME"`     // Sends TOKEN as a basic auth password, e.g. a Bitbucket app password
+	AuthMode    string `envconfig:"AUTH_MODE"`    // bearer, basic, token or oauth2; defaults per provider
+
+	// GitHub App authentication, used instead of TOKEN
+	GitHubAppID          int64  `envconfig:"GITHUB_APP_ID"`
+	GitHubInstallationID int64  `envconfig:"GITHUB_INSTALLATION_ID"`
+	GitHubPrivateKey     string `envconfig:"GITHUB_PRIVATE_KEY"`      // PEM encoded private key
+	GitHubPrivateKeyFile string `envconfig:"GITHUB_PRIVATE_KEY_FILE"` // Path to the PEM encoded private key
+
+	// OAuth2 token renewal for Bitbucket Cloud and GitLab
+	OAuthGrantType    string   `envconfig:"OAUTH_GRANT_TYPE"` // refresh_token or client_credentials
+	OAuthClientID     string   `envconfig:"OAUTH_CLIENT_ID"`
+	OAuthClientSecret string   `envconfig:"OAUTH_CLIENT_SECRET"`
+	OAuthRefreshToken string   `envconfig:"OAUTH_REFRESH_TOKEN"`
+	OAuthTokenURL     string   `envconfig:"OAUTH_TOKEN_URL"` // Overrides the provider's token endpoint
+	OAuthScopes       []string `envconfig:"OAUTH_SCOPES"`
 
 	// Repository
-	Repo      string `envconfig:"REPO" required:"true"` // owner/repo
-	PRNumber  int    `envconfig:"PR_NUMBER"`
-	CommitSHA string `envconfig:"COMMIT_SHA"`
+	Repo         string `envconfig:"REPO" required:"true"` // owner/repo
+	PRNumber     int    `envconfig:"PR_NUMBER"`            // Looked up from COMMIT_SHA or SOURCE_BRANCH when empty
+	CommitSHA    string `envconfig:"COMMIT_SHA"`
+	SourceBranch string `envconfig:"SOURCE_BRANCH"` // Source branch of the pull request, for looking it up
 
 	// Comment
 	CommentBody string `envconfig:"COMMENT_BODY"`
@@ -19,8 +36,10 @@ type Config struct {
 	FilePath string `envconfig:"FILE_PATH"`
 	Line     int    `envconfig:"LINE"`
 
-	// Batch Comments from JSON file
-	CommentsFile string `envconfig:"COMMENTS_FILE"` // Path to JSON file with array of comments
+	// Batch Comments from JSON files
+	CommentsFiles []string `envconfig:"COMMENTS_FILE"` // Comma-separated paths or glob patterns (supports **)
+	Strict        bool     `envconfig:"STRICT"`        // Fail on invalid reviews instead of skipping them
+	ReviewEvent   string   `envconfig:"REVIEW_EVENT"`  // comment (default), approve, request_changes, auto
 
 	// Status
 	StatusState   string `envconfig:"STATUS_STATE"`
@@ -28,18 +47,61 @@ type Config struct {
 	StatusDesc    string `envconfig:"STATUS_DESC"`
 	StatusURL     string `envconfig:"STATUS_URL"`
 
+	// Harness Code check report and timing
+	StatusDetails     string `envconfig:"STATUS_DETAILS"`      // Detailed report shown with the check
+	StatusPayloadKind string `envconfig:"STATUS_PAYLOAD_KIND"` // markdown (default) or raw
+	StatusStarted     string `envconfig:"STATUS_STARTED"`      // Start time of a finalized check: unix seconds or RFC 3339
+
+	// Pull request labels, updated after a comments file is posted
+	LabelsAdd    []string `envconfig:"LABELS_ADD"`
+	LabelsRemove []string `envconfig:"LABELS_REMOVE"`
+	LabelRules   []string `envconfig:"LABEL_RULES"` // label=severity: added when a finding is at least that severe, removed otherwise
+
+	// Reviewers requested from CODEOWNERS after a comments file is posted
+	CodeownersReviewers bool   `envconfig:"CODEOWNERS_REVIEWERS"`
+	CodeownersFile      string `envconfig:"CODEOWNERS_FILE"`     // Defaults to the first CODEOWNERS in .github/, .gitlab/, .gitea/, .harness/, the root or docs/
+	CodeownersSeverity  string `envconfig:"CODEOWNERS_SEVERITY"` // Only files with findings at least this severe, default info
+
+	// Pull request description section, delimited by markers
+	DescriptionBody    string `envconfig:"DESCRIPTION_BODY"`    // Written between the markers
+	DescriptionSummary bool   `envconfig:"DESCRIPTION_SUMMARY"` // Write the findings summary between the markers after a comments file is posted
+	DescriptionStart   string `envconfig:"DESCRIPTION_START"`   // Defaults to <!-- comment-plugin:start -->
+	DescriptionEnd     string `envconfig:"DESCRIPTION_END"`     // Defaults to <!-- comment-plugin:end -->
+
+	// Checks publishes statuses and batch findings as check runs with
+	// annotations (GitHub) instead of commit statuses and review comments
+	Checks bool `envconfig:"CHECKS"`
+
 	// Harness Code
 	HarnessAccountID string `envconfig:"HARNESS_ACCOUNT_ID"`
 	HarnessOrgID     string `envconfig:"HARNESS_ORG_ID"`
 	HarnessProjectID string `envconfig:"HARNESS_PROJECT_ID"`
 
+	// Azure DevOps
+	AzureOrganization string `envconfig:"AZURE_ORGANIZATION"`
+	AzureProject      string `envconfig:"AZURE_PROJECT"`
+	AzureThreadStatus string `envconfig:"AZURE_THREAD_STATUS"` // active, pending, fixed, wontFix, closed, byDesign
+	AzureResolveFixed bool   `envconfig:"AZURE_RESOLVE_FIXED"` // Mark threads of findings no longer reported as fixed
+
+	// Gerrit (PR_NUMBER is the change number, COMMIT_SHA the revision)
+	GerritLabels []string `envconfig:"GERRIT_LABELS"` // Label votes, e.g. Code-Review=-1,Verified=+1
+
 	// Debug
 	Debug  bool `envconfig:"DEBUG"`
 	DryRun bool `envconfig:"DRY_RUN"`
 }
 
+// Reviews file schema versions. Files without a version are version 1.
+const (
+	ReviewsVersion1 = 1
+	ReviewsVersion2 = 2
+
+	LatestReviewsVersion = ReviewsVersion2
+)
+
 // ReviewsFile represents the top-level structure of the reviews JSON file
 type ReviewsFile struct {
+	Version int             `json:"version,omitempty"`
 	Reviews []ReviewComment `json:"reviews"`
 }
 
@@ -50,4 +112,18 @@ type ReviewComment struct {
 	LineNumberEnd   int    `json:"line_number_end"`
 	Type            string `json:"type"` // issue|performance|scalability|code_smell|etc
 	Review          string `json:"review"`
+
+	// Version 2 fields
+	Severity         string   `json:"severity,omitempty"` // critical|high|medium|low|info
+	StartColumn      int      `json:"start_column,omitempty"`
+	EndColumn        int      `json:"end_column,omitempty"`
+	RuleID           string   `json:"rule_id,omitempty"`
+	DocumentationURL string   `json:"documentation_url,omitempty"`
+	Suggestion       string   `json:"suggestion,omitempty"` // Replacement for lines start..end
+	Confidence       float64  `json:"confidence,omitempty"` // 0..1
+	Tags             []string `json:"tags,omitempty"`
+	Fingerprint      string   `json:"fingerprint,omitempty"`
+
+	// Source is the comments file the review was loaded from
+	Source string `json:"-"`
 }
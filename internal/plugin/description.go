@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// Markers delimiting the section of the pull request description the plugin
+// owns, unless DESCRIPTION_START and DESCRIPTION_END are set
+const (
+	defaultDescriptionStart = "<!-- comment-plugin:start -->"
+	defaultDescriptionEnd   = "<!-- comment-plugin:end -->"
+)
+
+// descriptionMarkers returns the start and end markers of the description
+// section
+func descriptionMarkers(cfg Config) (string, string, error) {
+	start, end := cfg.DescriptionStart, cfg.DescriptionEnd
+	if start == "" {
+		start = defaultDescriptionStart
+	}
+	if end == "" {
+		end = defaultDescriptionEnd
+	}
+	if start == end {
+		return "", "", fmt.Errorf("DESCRIPTION_START and DESCRIPTION_END must differ")
+	}
+	return start, end, nil
+}
+
+// replaceSection replaces the content between the start and end markers of
+// body, keeping everything outside them. Without a start marker the section
+// is appended. A start marker without an end marker after it is an error, as
+// the extent of the section is unknown.
+func replaceSection(body, start, end, content string) (string, error) {
+	content = strings.TrimRight(content, "\r\n")
+
+	i := strings.Index(body, start)
+	if i < 0 {
+		section := start + "\n" + content + "\n" + end
+		body = strings.TrimRight(body, "\r\n")
+		if body == "" {
+			return section, nil
+		}
+		return body + "\n\n" + section, nil
+	}
+
+	i += len(start)
+	j := strings.Index(body[i:], end)
+	if j < 0 {
+		return "", fmt.Errorf("description has the start marker %q but no end marker %q after it", start, end)
+	}
+	return body[:i] + "\n" + content + "\n" + body[i+j:], nil
+}
+
+// updateDescriptionSummary writes the findings summary into the description
+// section after a comments file is posted, when DESCRIPTION_SUMMARY is set
+func (p *Plugin) updateDescriptionSummary(ctx context.Context, reviews []ReviewComment) error {
+	if !p.config.DescriptionSummary {
+		return nil
+	}
+	if !p.supports(scmclient.CapabilityDescription) {
+		// Already reported by logConfigDegradations
+		return nil
+	}
+	if p.config.PRNumber == 0 {
+		p.log.Warn("the description summary needs PR_NUMBER, skipping")
+		return nil
+	}
+	return p.updateDescription(ctx, summaryMarkdown(reviews))
+}
+
+// updateDescription replaces the plugin's section of the pull request
+// description with content, leaving the author's text untouched
+func (p *Plugin) updateDescription(ctx context.Context, content string) error {
+	if p.config.PRNumber == 0 {
+		return fmt.Errorf("PR_NUMBER is required to update the description")
+	}
+	if !p.supports(scmclient.CapabilityDescription) {
+		return fmt.Errorf("%s does not support updating the pull request description", p.provider)
+	}
+
+	start, end, err := descriptionMarkers(p.config)
+	if err != nil {
+		return err
+	}
+
+	pr, err := p.pullRequest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	description, err := replaceSection(pr.Body, start, end, content)
+	if err != nil {
+		return err
+	}
+	if description == pr.Body {
+		p.log.Info("pull request description is up to date")
+		return nil
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"repo":      p.config.Repo,
+		"pr_number": p.config.PRNumber,
+	}).Info("updating pull request description")
+
+	if err := p.descriptionClient().UpdateDescription(ctx, p.config.Repo, p.config.PRNumber, description); err != nil {
+		return err
+	}
+	pr.Body = description
+	return nil
+}
+
+// describer replaces the description of a pull request on one provider
+type describer interface {
+	UpdateDescription(ctx context.Context, repo string, prNumber int, description string) error
+}
+
+// descriptionClient returns the client that updates descriptions for the
+// provider. Only called for providers with the description capability.
+func (p *Plugin) descriptionClient() describer {
+	client, _ := p.native.(describer)
+	return client
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestReplaceSection(t *testing.T) {
+	const start, end = "<!-- s -->", "<!-- e -->"
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"empty body", "", "<!-- s -->\nnew\n<!-- e -->", false},
+		{"appended", "Fixes a bug.\n", "Fixes a bug.\n\n<!-- s -->\nnew\n<!-- e -->", false},
+		{"replaced", "Intro\n<!-- s -->\nold\nlines\n<!-- e -->\nOutro", "Intro\n<!-- s -->\nnew\n<!-- e -->\nOutro", false},
+		{"empty section", "<!-- s --><!-- e -->", "<!-- s -->\nnew\n<!-- e -->", false},
+		{"end before start", "<!-- e -->\n<!-- s -->\nold", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replaceSection(tt.body, start, end, "new\n")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("replaceSection error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("replaceSection = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsEqualDescriptionMarkers(t *testing.T) {
+	_, err := New(Config{SCMProvider: "github", Token: "token", Repo: "owner/repo", DescriptionStart: "<!-- x -->", DescriptionEnd: "<!-- x -->"})
+	if err == nil {
+		t.Fatal("New should reject equal description markers")
+	}
+}
+
+func TestUpdateDescriptionGitea(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var update map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/pulls/2" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"number": 2, "body": "Author text\n<!-- comment-plugin:start -->\nold\n<!-- comment-plugin:end -->"}`))
+		case http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider:     "gitea",
+		SCMEndpoint:     srv.URL,
+		Token:           "token",
+		Repo:            "owner/repo",
+		PRNumber:        2,
+		DescriptionBody: "new",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	want := "Author text\n<!-- comment-plugin:start -->\nnew\n<!-- comment-plugin:end -->"
+	if update["body"] != want {
+		t.Errorf("body = %q, want %q", update["body"], want)
+	}
+}
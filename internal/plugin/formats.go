@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Reviews file encodings
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+)
+
+// tomlKey matches a TOML table header or key assignment at the start of a line
+var tomlKey = regexp.MustCompile(`(?m)^\s*(\[\[?[A-Za-z0-9_."-]+\]\]?|[A-Za-z0-9_-]+\s*=)`)
+
+// detectFormat picks the encoding of a reviews file from its extension,
+// falling back to sniffing the content.
+func detectFormat(file string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return formatJSON
+	case tomlKey.Match(trimmed):
+		return formatTOML
+	default:
+		return formatYAML
+	}
+}
+
+// toJSON converts a YAML or TOML reviews document into JSON so that all
+// encodings share the same schema validation.
+func toJSON(format string, data []byte) ([]byte, error) {
+	var doc interface{}
+
+	switch format {
+	case formatJSON:
+		return data, nil
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case formatTOML:
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+		doc = table
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		want string
+	}{
+		{"reviews.json", "", formatJSON},
+		{"reviews.yml", "", formatYAML},
+		{"reviews.YAML", "", formatYAML},
+		{"reviews.toml", "", formatTOML},
+		{"reviews", `  {"reviews": []}`, formatJSON},
+		{"reviews", "version = 2\n[[reviews]]\nfile_path = \"a.go\"", formatTOML},
+		{"reviews", "reviews:\n  - file_path: a.go", formatYAML},
+	}
+
+	for _, tt := range tests {
+		if got := detectFormat(tt.file, []byte(tt.data)); got != tt.want {
+			t.Errorf("detectFormat(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestYAMLAndTOMLReviews(t *testing.T) {
+	yamlDoc := `
+version: 2
+reviews:
+  - file_path: src/main.go
+    line_number_start: 10
+    line_number_end: 12
+    type: bug
+    severity: high
+    tags: [safety]
+    review: |
+      First line.
+      Second line.
+`
+	tomlDoc := `
+version = 2
+
+[[reviews]]
+file_path = "src/main.go"
+line_number_start = 10
+line_number_end = 12
+type = "bug"
+severity = "high"
+tags = ["safety"]
+review = """
+First line.
+Second line.
+"""
+`
+
+	for format, doc := range map[string]string{formatYAML: yamlDoc, formatTOML: tomlDoc} {
+		data, err := toJSON(format, []byte(doc))
+		if err != nil {
+			t.Fatalf("%s: toJSON failed: %v", format, err)
+		}
+
+		reviews, errs, err := validateReviews(data)
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("%s: validateReviews failed: %v %v", format, err, errs)
+		}
+		if len(reviews) != 1 {
+			t.Fatalf("%s: got %d reviews, want 1", format, len(reviews))
+		}
+
+		r := reviews[0]
+		if r.FilePath != "src/main.go" || r.LineNumberStart != 10 || r.LineNumberEnd != 12 || r.Severity != "high" {
+			t.Errorf("%s: unexpected review %+v", format, r)
+		}
+		if r.Review != "First line.\nSecond line.\n" {
+			t.Errorf("%s: multi-line review = %q", format, r.Review)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/abhinav-harness/comment-plugin/internal/gerrit"
+	"github.com/sirupsen/logrus"
+)
+
+// gerritTag marks the plugin's change messages as bot output so the Gerrit
+// UI can hide them alongside other automated messages
+const gerritTag = "autogenerated:" + defaultCheckName
+
+// parseLabels parses GERRIT_LABELS entries of the form Label=vote
+func parseLabels(entries []string) (map[string]int, error) {
+	labels := make(map[string]int)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		vote, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || strings.TrimSpace(name) == "" || err != nil {
+			return nil, fmt.Errorf("invalid GERRIT_LABELS entry %q, expected Label=vote", entry)
+		}
+		labels[strings.TrimSpace(name)] = vote
+	}
+	return labels, nil
+}
+
+// gerritReview posts a review on the change with the configured label votes
+func (p *Plugin) gerritReview(ctx context.Context, input gerrit.ReviewInput) error {
+	if p.config.PRNumber == 0 {
+		return fmt.Errorf("PR_NUMBER (change number) is required")
+	}
+	if input.Labels == nil {
+		input.Labels = p.gerritLabels
+	}
+	input.Tag = gerritTag
+	return p.gerrit.SetReview(ctx, p.config.Repo, p.config.PRNumber, p.config.CommitSHA, input)
+}
+
+// createGerritReviewFromReviews posts the batch as one review with a robot
+// comment per finding
+func (p *Plugin) createGerritReviewFromReviews(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	files := make(map[string][]string)
+	comments := make([]gerrit.RobotComment, 0, len(reviews))
+	for _, review := range reviews {
+		endCharacter := -1
+		if review.LineNumberStart < review.LineNumberEnd || review.Suggestion != "" {
+			endCharacter = p.gerritLineLength(ctx, files, review.FilePath, review.LineNumberEnd)
+		}
+		comments = append(comments, p.robotComment(review, opts, endCharacter))
+	}
+
+	err := p.gerritReview(ctx, gerrit.ReviewInput{
+		Message:       p.reviewBodyText(reviews),
+		RobotComments: comments,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.log.WithField("count", len(reviews)).Info("posted Gerrit review with robot comments")
+	return nil
+}
+
+// gerritLineLength returns the length of a line of a file in the revision, or
+// -1 when the file cannot be read. files caches the lines of each file read.
+func (p *Plugin) gerritLineLength(ctx context.Context, files map[string][]string, path string, line int) int {
+	lines, ok := files[path]
+	if !ok {
+		content, err := p.gerrit.GetFileContent(ctx, p.config.Repo, p.config.PRNumber, p.config.CommitSHA, path)
+		if err != nil {
+			p.log.WithError(err).WithField("path", path).Warn("failed to read file, its comments lose their ranges and fix suggestions")
+		} else {
+			lines = strings.Split(string(content), "\n")
+		}
+		files[path] = lines
+	}
+
+	if line < 1 || line > len(lines) {
+		return -1
+	}
+	// Gerrit counts characters in UTF-16 code units
+	return len(utf16.Encode([]rune(strings.TrimSuffix(lines[line-1], "\r"))))
+}
+
+// robotComment converts a review into a robot comment. A suggestion becomes
+// a fix suggestion that replaces the whole commented line range. Ranges end
+// at endCharacter on the last line; when it is unknown (-1) the comment is
+// anchored to the last line and the suggestion is shown in the message.
+func (p *Plugin) robotComment(review ReviewComment, opts renderOptions, endCharacter int) gerrit.RobotComment {
+	if endCharacter < 0 && review.Suggestion != "" {
+		opts.Suggestions = suggestionFenced
+	}
+
+	comment := gerrit.RobotComment{
+		Comment: gerrit.Comment{
+			Path:    review.FilePath,
+			Line:    review.LineNumberEnd,
+			Message: formatReview(review, opts),
+		},
+		RobotID:    defaultCheckName,
+		RobotRunID: p.config.CommitSHA,
+		URL:        review.DocumentationURL,
+	}
+	if comment.RobotRunID == "" {
+		comment.RobotRunID = fmt.Sprint(p.config.PRNumber)
+	}
+
+	if review.LineNumberStart < review.LineNumberEnd && endCharacter >= 0 {
+		comment.Range = &gerrit.Range{
+			StartLine:    review.LineNumberStart,
+			EndLine:      review.LineNumberEnd,
+			EndCharacter: endCharacter,
+		}
+	}
+
+	properties := make(map[string]string)
+	if review.RuleID != "" {
+		properties["rule_id"] = review.RuleID
+	}
+	if review.Severity != "" {
+		properties["severity"] = severityOf(review)
+	}
+	if len(properties) > 0 {
+		comment.Properties = properties
+	}
+
+	if review.Suggestion != "" && endCharacter >= 0 {
+		comment.FixSuggestions = []gerrit.FixSuggestion{{
+			Description: "Apply suggested change",
+			Replacements: []gerrit.Replacement{{
+				Path: review.FilePath,
+				// Whole lines, up to but not including the last line break
+				Range: gerrit.Range{
+					StartLine:    review.LineNumberStart,
+					EndLine:      review.LineNumberEnd,
+					EndCharacter: endCharacter,
+				},
+				Replacement: strings.TrimSuffix(review.Suggestion, "\n"),
+			}},
+		}}
+	}
+
+	return comment
+}
+
+// createGerritStatus reports STATUS_STATE as a change message with the votes
+// from statusLabels
+func (p *Plugin) createGerritStatus(ctx context.Context) error {
+	name := p.config.StatusContext
+	if name == "" {
+		name = defaultCheckName
+	}
+
+	message := fmt.Sprintf("%s: %s", name, p.config.StatusState)
+	if p.config.StatusDesc != "" {
+		message += " - " + p.config.StatusDesc
+	}
+	if p.config.StatusURL != "" {
+		message += "\n\n" + p.config.StatusURL
+	}
+
+	labels := statusLabels(p.gerritLabels, p.config.StatusState)
+
+	if err := p.gerritReview(ctx, gerrit.ReviewInput{Message: message, Labels: labels}); err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{"state": p.config.StatusState, "labels": labels}).Info("posted Gerrit status")
+	return nil
+}
+
+// statusLabels returns the votes for a status. GERRIT_LABELS are voted on
+// success; on failure or error their positive votes are negated, and while
+// pending nothing is voted. Without GERRIT_LABELS, success votes Verified+1
+// and failure or error Verified-1.
+func statusLabels(configured map[string]int, state string) map[string]int {
+	if len(configured) == 0 {
+		return verifiedVote(state)
+	}
+
+	switch strings.ToLower(state) {
+	case "success":
+		return configured
+	case "failure", "failed", "error":
+		labels := make(map[string]int, len(configured))
+		for name, vote := range configured {
+			labels[name] = -abs(vote)
+		}
+		return labels
+	default:
+		// Not nil, so gerritReview does not fall back to GERRIT_LABELS
+		return map[string]int{}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func verifiedVote(state string) map[string]int {
+	switch strings.ToLower(state) {
+	case "success":
+		return map[string]int{"Verified": 1}
+	case "failure", "failed", "error":
+		return map[string]int{"Verified": -1}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"Code-Review=-1", " Verified=+1 ", ""})
+	if err != nil {
+		t.Fatalf("parseLabels failed: %v", err)
+	}
+	if labels["Code-Review"] != -1 || labels["Verified"] != 1 || len(labels) != 2 {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	for _, bad := range []string{"Verified", "=1", "Verified=yes"} {
+		if _, err := parseLabels([]string{bad}); err == nil {
+			t.Errorf("parseLabels(%q) should fail", bad)
+		}
+	}
+}
+
+func TestVerifiedVote(t *testing.T) {
+	tests := map[string]int{"success": 1, "failure": -1, "error": -1}
+	for state, want := range tests {
+		if got := verifiedVote(state)["Verified"]; got != want {
+			t.Errorf("verifiedVote(%q) = %d, want %d", state, got, want)
+		}
+	}
+	if votes := verifiedVote("pending"); votes != nil {
+		t.Errorf("pending should not vote, got %v", votes)
+	}
+}
+
+func TestStatusLabels(t *testing.T) {
+	configured := map[string]int{"Verified": 1, "Code-Review": -1}
+
+	tests := []struct {
+		state string
+		want  map[string]int
+	}{
+		{"success", map[string]int{"Verified": 1, "Code-Review": -1}},
+		{"failure", map[string]int{"Verified": -1, "Code-Review": -1}},
+		{"error", map[string]int{"Verified": -1, "Code-Review": -1}},
+		{"pending", map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		got := statusLabels(configured, tt.state)
+		if got == nil || len(got) != len(tt.want) {
+			t.Errorf("statusLabels(%q) = %v, want %v", tt.state, got, tt.want)
+			continue
+		}
+		for name, vote := range tt.want {
+			if got[name] != vote {
+				t.Errorf("statusLabels(%q) = %v, want %v", tt.state, got, tt.want)
+			}
+		}
+	}
+
+	if got := statusLabels(nil, "success"); got["Verified"] != 1 {
+		t.Errorf("without GERRIT_LABELS success should vote Verified+1, got %v", got)
+	}
+}
+
+func TestRobotCommentSuggestion(t *testing.T) {
+	p := &Plugin{config: Config{CommitSHA: "abc"}}
+	review := ReviewComment{
+		FilePath:        "main.go",
+		LineNumberStart: 4,
+		LineNumberEnd:   5,
+		Type:            "bug",
+		Review:          "off by one",
+		RuleID:          "R1",
+		Suggestion:      "for i := 0; i < n; i++ {",
+	}
+
+	comment := p.robotComment(review, renderOptions{Suggestions: suggestionNone}, 12)
+	if comment.Range == nil || comment.Range.StartLine != 4 || comment.Range.EndLine != 5 || comment.Range.EndCharacter != 12 {
+		t.Errorf("unexpected range %+v", comment.Range)
+	}
+	if comment.RobotRunID != "abc" || comment.Properties["rule_id"] != "R1" {
+		t.Errorf("unexpected robot fields %+v", comment)
+	}
+	if strings.Contains(comment.Message, "```") {
+		t.Errorf("suggestion should not be repeated in the message: %q", comment.Message)
+	}
+	if len(comment.FixSuggestions) != 1 {
+		t.Fatalf("expected one fix suggestion, got %d", len(comment.FixSuggestions))
+	}
+	replacement := comment.FixSuggestions[0].Replacements[0]
+	if replacement.Replacement != review.Suggestion || replacement.Range.EndLine != 5 || replacement.Range.EndCharacter != 12 {
+		t.Errorf("unexpected replacement %+v", replacement)
+	}
+
+	// Without the line length the suggestion is shown in the message instead
+	comment = p.robotComment(review, renderOptions{Suggestions: suggestionNone}, -1)
+	if comment.Range != nil || comment.Line != 5 || len(comment.FixSuggestions) != 0 {
+		t.Errorf("unexpected comment without line length %+v", comment)
+	}
+	if !strings.Contains(comment.Message, review.Suggestion) {
+		t.Errorf("suggestion missing from the message: %q", comment.Message)
+	}
+}
+
+func TestGerritReviewLineLengths(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var reads int
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/content") {
+			reads++
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("package main\n\nfunc héllo() {\r\n}\n"))))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(")]}'\n{}"))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{SCMProvider: "gerrit", SCMEndpoint: srv.URL, Token: "secret", Repo: "tools/plugin", PRNumber: 12, CommitSHA: "abc"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	reviews := []ReviewComment{
+		{FilePath: "main.go", LineNumberStart: 1, LineNumberEnd: 3, Review: "range"},
+		{FilePath: "main.go", LineNumberStart: 3, LineNumberEnd: 3, Review: "fix", Suggestion: "func hello() {\n"},
+	}
+	if err := p.createGerritReviewFromReviews(context.Background(), reviews, renderOptions{Suggestions: suggestionNone}); err != nil {
+		t.Fatalf("createGerritReviewFromReviews failed: %v", err)
+	}
+
+	if reads != 1 {
+		t.Errorf("file read %d times, want once", reads)
+	}
+	comments := body["robot_comments"].(map[string]interface{})["main.go"].([]interface{})
+	rng := comments[0].(map[string]interface{})["range"].(map[string]interface{})
+	if rng["end_line"] != float64(3) || rng["end_character"] != float64(14) {
+		t.Errorf("unexpected range %v", rng)
+	}
+	fix := comments[1].(map[string]interface{})["fix_suggestions"].([]interface{})[0].(map[string]interface{})
+	replacement := fix["replacements"].([]interface{})[0].(map[string]interface{})
+	if replacement["replacement"] != "func hello() {" {
+		t.Errorf("unexpected replacement %v", replacement)
+	}
+}
+
+func TestGerritStatus(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(")]}'\n{}"))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider:   "gerrit",
+		SCMEndpoint:   srv.URL,
+		Username:      "ci",
+		Token:         "secret",
+		Repo:          "tools/plugin",
+		PRNumber:      12,
+		CommitSHA:     "abc",
+		StatusState:   "failure",
+		StatusContext: "lint",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if path != "/a/changes/tools%2Fplugin~12/revisions/abc/review" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if body["message"] != "lint: failure" || body["tag"] != gerritTag {
+		t.Errorf("unexpected review %v", body)
+	}
+	if labels := body["labels"].(map[string]interface{}); labels["Verified"] != float64(-1) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+}
@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abhinav-harness/comment-plugin/internal/bitbucket"
+	"github.com/abhinav-harness/comment-plugin/internal/bitbucketserver"
+	"github.com/sirupsen/logrus"
+)
+
+// Code Insights limits on annotation text
+const (
+	maxInsightsSummary = 450
+	maxInsightsDetails = 2000
+)
+
+// createInsightsReport publishes the batch as a Bitbucket Code Insights
+// report on the head commit, with one annotation per finding. The report is
+// keyed by the check name so each run replaces the previous one.
+func (p *Plugin) createInsightsReport(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	sha, err := p.headSHA(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.bitbucketServer != nil {
+		err = p.createServerInsightsReport(ctx, sha, reviews, opts)
+	} else {
+		err = p.createCloudInsightsReport(ctx, sha, reviews, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"report": p.checkName(),
+		"count":  len(reviews),
+	}).Info("created Code Insights report from reviews")
+	return nil
+}
+
+func (p *Plugin) createCloudInsightsReport(ctx context.Context, sha string, reviews []ReviewComment, opts renderOptions) error {
+	ids := insightsExternalIDs(reviews)
+	annotations := make([]bitbucket.Annotation, 0, len(reviews))
+	for i, review := range reviews {
+		annotations = append(annotations, bitbucket.Annotation{
+			ExternalID: ids[i],
+			Path:       review.FilePath,
+			Line:       review.LineNumberStart,
+			Summary:    insightsSummary(review),
+			Details:    truncate(annotationMessage(review, opts), maxInsightsDetails),
+			Severity:   insightsSeverity(severityOf(review)),
+			Type:       insightsAnnotationType(review),
+			Link:       review.DocumentationURL,
+		})
+	}
+
+	return p.bitbucket.CreateReport(ctx, p.config.Repo, sha, p.checkName(), bitbucket.ReportInput{
+		Title:       p.checkName(),
+		Details:     insightsDetails(reviews),
+		ReportType:  "BUG",
+		Reporter:    defaultCheckName,
+		Result:      insightsResult(reviews),
+		Link:        p.config.StatusURL,
+		Annotations: annotations,
+	})
+}
+
+// createServerInsightsReport publishes the report to Bitbucket Server, whose
+// annotations take a single message and only three severities. The report
+// always carries a result, so a repository that requires it as a merge check
+// blocks merging while it fails.
+func (p *Plugin) createServerInsightsReport(ctx context.Context, sha string, reviews []ReviewComment, opts renderOptions) error {
+	ids := insightsExternalIDs(reviews)
+	annotations := make([]bitbucketserver.Annotation, 0, len(reviews))
+	for i, review := range reviews {
+		severity := insightsSeverity(severityOf(review))
+		if severity == "CRITICAL" {
+			severity = "HIGH"
+		}
+
+		message := insightsSummary(review)
+		if details := annotationMessage(review, opts); details != firstLine(review.Review) {
+			message = truncate(message+"\n\n"+details, maxInsightsDetails)
+		}
+
+		annotations = append(annotations, bitbucketserver.Annotation{
+			ExternalID: ids[i],
+			Path:       review.FilePath,
+			Line:       review.LineNumberStart,
+			Message:    message,
+			Severity:   severity,
+			Type:       insightsAnnotationType(review),
+			Link:       review.DocumentationURL,
+		})
+	}
+
+	result := "PASS"
+	if insightsResult(reviews) == "FAILED" {
+		result = "FAIL"
+	}
+
+	return p.bitbucketServer.CreateReport(ctx, p.config.Repo, sha, insightsReportKey(p.checkName()), bitbucketserver.ReportInput{
+		Title:       p.checkName(),
+		Details:     insightsDetails(reviews),
+		Reporter:    defaultCheckName,
+		Result:      result,
+		Link:        p.config.StatusURL,
+		Annotations: annotations,
+	})
+}
+
+// insightsReportKey turns the check name into a report key, which Bitbucket
+// Server uses in URLs: anything but letters, digits, dots, dashes and
+// underscores becomes a dash
+func insightsReportKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// insightsResult fails the report on the same findings that fail a GitHub
+// check: anything critical or high.
+func insightsResult(reviews []ReviewComment) string {
+	if checkConclusion(reviews) == "failure" {
+		return "FAILED"
+	}
+	return "PASSED"
+}
+
+func insightsSeverity(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "CRITICAL"
+	case SeverityHigh:
+		return "HIGH"
+	case SeverityMedium:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+func insightsAnnotationType(review ReviewComment) string {
+	switch strings.ToLower(review.Type) {
+	case "secret", "security", "vulnerability":
+		return "VULNERABILITY"
+	case "bug":
+		return "BUG"
+	default:
+		return "CODE_SMELL"
+	}
+}
+
+// insightsDetails is a plain-text count of findings per severity
+func insightsDetails(reviews []ReviewComment) string {
+	counts := countBySeverity(reviews)
+	var totals []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			totals = append(totals, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	if len(totals) == 0 {
+		return summaryTitle(reviews)
+	}
+	return summaryTitle(reviews) + ": " + strings.Join(totals, ", ")
+}
+
+func insightsSummary(review ReviewComment) string {
+	summary := firstLine(review.Review)
+	if title := annotationTitle(review); title != "" {
+		summary = title + ": " + summary
+	}
+	return truncate(summary, maxInsightsSummary)
+}
+
+// insightsExternalIDs derives a stable, unique annotation ID per review from
+// its fingerprint
+func insightsExternalIDs(reviews []ReviewComment) []string {
+	ids := make([]string, len(reviews))
+	seen := make(map[string]int)
+	for i, review := range reviews {
+		id := review.Fingerprint
+		if id == "" {
+			id = reviewFingerprint(review)
+		}
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// truncate shortens text to at most limit characters, marking the cut
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestInsightsResult(t *testing.T) {
+	tests := []struct {
+		severities []string
+		want       string
+	}{
+		{nil, "PASSED"},
+		{[]string{"medium", "low"}, "PASSED"},
+		{[]string{"low", "high"}, "FAILED"},
+		{[]string{"critical"}, "FAILED"},
+	}
+
+	for _, tt := range tests {
+		var reviews []ReviewComment
+		for _, severity := range tt.severities {
+			reviews = append(reviews, ReviewComment{Severity: severity})
+		}
+		if got := insightsResult(reviews); got != tt.want {
+			t.Errorf("insightsResult(%v) = %q, want %q", tt.severities, got, tt.want)
+		}
+	}
+}
+
+func TestInsightsAnnotationType(t *testing.T) {
+	tests := map[string]string{
+		"secret":      "VULNERABILITY",
+		"Security":    "VULNERABILITY",
+		"bug":         "BUG",
+		"performance": "CODE_SMELL",
+		"":            "CODE_SMELL",
+	}
+
+	for reviewType, want := range tests {
+		if got := insightsAnnotationType(ReviewComment{Type: reviewType}); got != want {
+			t.Errorf("insightsAnnotationType(%q) = %q, want %q", reviewType, got, want)
+		}
+	}
+}
+
+func TestInsightsDetails(t *testing.T) {
+	reviews := []ReviewComment{{Severity: "high"}, {Severity: "low"}, {}}
+	if got, want := insightsDetails(reviews), "3 findings: 1 high, 1 medium, 1 low"; got != want {
+		t.Errorf("insightsDetails() = %q, want %q", got, want)
+	}
+	if got := insightsDetails(nil); got != "No findings" {
+		t.Errorf("insightsDetails(nil) = %q", got)
+	}
+}
+
+func TestInsightsSummaryTruncates(t *testing.T) {
+	review := ReviewComment{Type: "bug", Review: strings.Repeat("é", 500) + "\nsecond line"}
+
+	summary := insightsSummary(review)
+	if n := len([]rune(summary)); n != maxInsightsSummary {
+		t.Errorf("summary has %d characters, want %d", n, maxInsightsSummary)
+	}
+	if !strings.HasPrefix(summary, "bug: ") || !strings.HasSuffix(summary, "…") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+}
+
+func TestInsightsExternalIDsAreUnique(t *testing.T) {
+	reviews := []ReviewComment{
+		{Fingerprint: "abc"},
+		{Fingerprint: "abc"},
+		{Fingerprint: "def"},
+	}
+
+	ids := insightsExternalIDs(reviews)
+	want := []string{"abc", "abc-2", "def"}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("ids[%d] = %q, want %q", i, ids[i], want[i])
+		}
+	}
+}
+
+func TestInsightsReportKey(t *testing.T) {
+	tests := map[string]string{
+		"ai-review":        "ai-review",
+		"ci/lint":          "ci-lint",
+		"AI Review v1.2_x": "AI-Review-v1.2_x",
+	}
+	for name, want := range tests {
+		if got := insightsReportKey(name); got != want {
+			t.Errorf("insightsReportKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestServerInsightsReportResult(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var paths []string
+	var report map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == http.MethodPut {
+			_ = json.NewDecoder(r.Body).Decode(&report)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider:   "bitbucket-server",
+		SCMEndpoint:   srv.URL,
+		Token:         "token",
+		Repo:          "PRJ/repo",
+		CommitSHA:     "abc",
+		StatusContext: "ci/ai review",
+		Checks:        true,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	reviews := []ReviewComment{{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Severity: SeverityHigh, Review: "bug"}}
+	if err := p.postReviews(context.Background(), reviews, renderOptions{}); err != nil {
+		t.Fatalf("postReviews failed: %v", err)
+	}
+
+	// Only the report is published; merge checks are left to the repository settings
+	want := []string{
+		"PUT /rest/insights/1.0/projects/PRJ/repos/repo/commits/abc/reports/ci-ai-review",
+		"DELETE /rest/insights/1.0/projects/PRJ/repos/repo/commits/abc/reports/ci-ai-review/annotations",
+		"POST /rest/insights/1.0/projects/PRJ/repos/repo/commits/abc/reports/ci-ai-review/annotations",
+	}
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(paths, "\n"), strings.Join(want, "\n"))
+	}
+	if report["result"] != "FAIL" {
+		t.Errorf("unexpected report %v", report)
+	}
+}
@@ -0,0 +1,150 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// labelRule adds a label while any finding is at least as severe as
+// severity, and removes it once none is
+type labelRule struct {
+	label    string
+	severity string
+}
+
+// parseLabelRules parses LABEL_RULES entries such as needs-attention=critical
+func parseLabelRules(entries []string) ([]labelRule, error) {
+	var rules []labelRule
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid LABEL_RULES entry %q, expected label=severity", entry)
+		}
+		label := strings.TrimSpace(entry[:i])
+		severity := strings.ToLower(strings.TrimSpace(entry[i+1:]))
+		if !validSeverity(severity) {
+			return nil, fmt.Errorf("invalid severity %q in LABEL_RULES entry %q", severity, entry)
+		}
+		rules = append(rules, labelRule{label: label, severity: severity})
+	}
+	return rules, nil
+}
+
+// hasLabels reports whether any label setting is configured
+func (p *Plugin) hasLabels() bool {
+	return len(p.config.LabelsAdd) > 0 || len(p.config.LabelsRemove) > 0 || len(p.labelRules) > 0
+}
+
+// labelChanges returns the labels to add and remove: LABELS_ADD and
+// LABELS_REMOVE, plus the label of each rule depending on the findings. Rules
+// are only evaluated when a batch was posted. A label both added and removed
+// is added.
+func labelChanges(add, remove []string, rules []labelRule, reviews []ReviewComment, batch bool) ([]string, []string) {
+	add = trimLabels(add)
+	remove = trimLabels(remove)
+
+	if batch {
+		for _, rule := range rules {
+			if hasFindingAtLeast(reviews, rule.severity) {
+				add = append(add, rule.label)
+			} else {
+				remove = append(remove, rule.label)
+			}
+		}
+	}
+
+	added := make(map[string]bool, len(add))
+	var toAdd []string
+	for _, label := range add {
+		if !added[label] {
+			added[label] = true
+			toAdd = append(toAdd, label)
+		}
+	}
+
+	removed := make(map[string]bool, len(remove))
+	var toRemove []string
+	for _, label := range remove {
+		if !added[label] && !removed[label] {
+			removed[label] = true
+			toRemove = append(toRemove, label)
+		}
+	}
+	return toAdd, toRemove
+}
+
+func trimLabels(labels []string) []string {
+	var out []string
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+func hasFindingAtLeast(reviews []ReviewComment, severity string) bool {
+	for _, review := range reviews {
+		if severityRank(severityOf(review)) >= severityRank(severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateLabels adds and removes pull request labels. reviews are the posted
+// findings when batch is true, which LABEL_RULES are evaluated against.
+func (p *Plugin) updateLabels(ctx context.Context, reviews []ReviewComment, batch bool) error {
+	if !p.hasLabels() {
+		return nil
+	}
+	if !p.supports(scmclient.CapabilityLabels) {
+		// Already reported by logConfigDegradations
+		return nil
+	}
+	if p.config.PRNumber == 0 {
+		p.log.Warn("labels need PR_NUMBER, skipping")
+		return nil
+	}
+
+	add, remove := labelChanges(p.config.LabelsAdd, p.config.LabelsRemove, p.labelRules, reviews, batch)
+	p.log.WithFields(logrus.Fields{
+		"add":    add,
+		"remove": remove,
+	}).Info("updating pull request labels")
+
+	if len(add) > 0 {
+		if err := p.labelClient().AddLabels(ctx, p.config.Repo, p.config.PRNumber, add); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if err := p.labelClient().RemoveLabels(ctx, p.config.Repo, p.config.PRNumber, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labeler adds and removes pull request labels on one provider
+type labeler interface {
+	AddLabels(ctx context.Context, repo string, prNumber int, labels []string) error
+	RemoveLabels(ctx context.Context, repo string, prNumber int, labels []string) error
+}
+
+// labelClient returns the client that manages labels for the provider. Only
+// called for providers with the labels capability.
+func (p *Plugin) labelClient() labeler {
+	client, _ := p.native.(labeler)
+	return client
+}
@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelRules(t *testing.T) {
+	rules, err := parseLabelRules([]string{"needs-attention=Critical", " review::blocked = high ", ""})
+	if err != nil {
+		t.Fatalf("parseLabelRules failed: %v", err)
+	}
+	want := []labelRule{{"needs-attention", "critical"}, {"review::blocked", "high"}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+
+	for _, entry := range []string{"needs-attention", "=high", "label=urgent"} {
+		if _, err := parseLabelRules([]string{entry}); err == nil {
+			t.Errorf("parseLabelRules(%q) should fail", entry)
+		}
+	}
+}
+
+func TestLabelChanges(t *testing.T) {
+	rules := []labelRule{{"needs-attention", "critical"}, {"has-findings", "info"}}
+	reviews := []ReviewComment{{Severity: "high"}}
+
+	tests := []struct {
+		name       string
+		add        []string
+		remove     []string
+		reviews    []ReviewComment
+		batch      bool
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{"rules on findings", []string{"ai-reviewed"}, nil, reviews, true, []string{"ai-reviewed", "has-findings"}, []string{"needs-attention"}},
+		{"rules clear", nil, nil, nil, true, nil, []string{"needs-attention", "has-findings"}},
+		{"rules need a batch", []string{"ai-reviewed"}, []string{"wip"}, nil, false, []string{"ai-reviewed"}, []string{"wip"}},
+		{"add wins over remove", []string{"needs-attention"}, []string{" needs-attention", "wip", "wip"}, nil, true, []string{"needs-attention"}, []string{"wip", "has-findings"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := labelChanges(tt.add, tt.remove, rules, tt.reviews, tt.batch)
+			if !reflect.DeepEqual(add, tt.wantAdd) || !reflect.DeepEqual(remove, tt.wantRemove) {
+				t.Errorf("labelChanges = %v, %v, want %v, %v", add, remove, tt.wantAdd, tt.wantRemove)
+			}
+		})
+	}
+}
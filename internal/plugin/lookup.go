@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// prPageSize is the page size used when listing open pull requests;
+	// Gitea accepts at most 50
+	prPageSize = 50
+	// prMaxPages bounds how many open pull requests are searched
+	prMaxPages = 20
+)
+
+// needsPullRequest reports whether the configured action posts on a pull
+// request. Statuses are posted on COMMIT_SHA.
+func (p *Plugin) needsPullRequest() bool {
+	return len(p.config.CommentsFiles) > 0 || p.config.StatusState == ""
+}
+
+// findPullRequest sets PR_NUMBER to the open pull request whose head is
+// COMMIT_SHA or whose source branch is SOURCE_BRANCH. Finding none is only an
+// error when the action cannot be posted without a pull request.
+func (p *Plugin) findPullRequest(ctx context.Context) error {
+	log := p.log.WithFields(logrus.Fields{
+		"commit_sha":    p.config.CommitSHA,
+		"source_branch": p.config.SourceBranch,
+	})
+	log.Info("PR_NUMBER not set, looking up the pull request")
+
+	prs, err := p.listOpenPullRequests(ctx)
+	if errors.Is(err, scm.ErrNotSupported) {
+		return fmt.Errorf("%s does not support finding pull requests, set PR_NUMBER", p.provider)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	matches := matchPullRequests(prs, p.config.CommitSHA, p.config.SourceBranch)
+	switch len(matches) {
+	case 0:
+		if p.commitComments() || (p.config.Checks && len(p.config.CommentsFiles) > 0) {
+			log.Info("no open pull request found, posting on the commit")
+			return nil
+		}
+		return fmt.Errorf("no open pull request found for %s, set PR_NUMBER", p.lookupTarget())
+	case 1:
+		p.config.PRNumber = matches[0].Number
+		log.WithField("pr_number", p.config.PRNumber).Info("found pull request")
+		return nil
+	default:
+		numbers := make([]string, 0, len(matches))
+		for _, pr := range matches {
+			numbers = append(numbers, fmt.Sprintf("#%d", pr.Number))
+		}
+		return fmt.Errorf("several open pull requests found for %s (%s), set PR_NUMBER", p.lookupTarget(), strings.Join(numbers, ", "))
+	}
+}
+
+// listOpenPullRequests lists the repository's open pull requests, up to
+// prMaxPages pages
+func (p *Plugin) listOpenPullRequests(ctx context.Context) ([]*scm.PullRequest, error) {
+	var prs []*scm.PullRequest
+	opts := scm.PullRequestListOptions{Page: 1, Size: prPageSize, Open: true}
+	for i := 0; i < prMaxPages; i++ {
+		page, res, err := p.client.PullRequests.List(ctx, p.config.Repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, page...)
+		if res == nil || res.Page.Next <= opts.Page {
+			break
+		}
+		opts.Page = res.Page.Next
+	}
+	return prs, nil
+}
+
+// matchPullRequests returns the pull requests whose head is sha or whose
+// source branch is branch. When several match, those matching both are
+// preferred.
+func matchPullRequests(prs []*scm.PullRequest, sha, branch string) []*scm.PullRequest {
+	var matches, both []*scm.PullRequest
+	for _, pr := range prs {
+		bySHA := sha != "" && sameCommit(pr.Sha, sha)
+		byBranch := branch != "" && pr.Source == branch
+		if bySHA || byBranch {
+			matches = append(matches, pr)
+		}
+		if bySHA && byBranch {
+			both = append(both, pr)
+		}
+	}
+	if len(matches) > 1 && len(both) > 0 {
+		return both
+	}
+	return matches
+}
+
+// sameCommit compares commit SHAs, allowing the abbreviated SHAs some
+// providers (such as Bitbucket Cloud) return
+func sameCommit(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if len(a) < 7 || len(b) < 7 {
+		return a == b
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// lookupTarget describes what the pull request was looked up by
+func (p *Plugin) lookupTarget() string {
+	var target []string
+	if p.config.CommitSHA != "" {
+		target = append(target, "commit "+p.config.CommitSHA)
+	}
+	if p.config.SourceBranch != "" {
+		target = append(target, "branch "+p.config.SourceBranch)
+	}
+	return strings.Join(target, " or ")
+}
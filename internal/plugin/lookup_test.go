@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMatchPullRequests(t *testing.T) {
+	prs := []*scm.PullRequest{
+		{Number: 1, Sha: "aaaaaaaaaaaa", Source: "feature"},
+		{Number: 2, Sha: "bbbbbbbbbbbb", Source: "feature"},
+		{Number: 3, Sha: "cccccccccccc", Source: "fix"},
+	}
+
+	tests := []struct {
+		name   string
+		sha    string
+		branch string
+		want   []int
+	}{
+		{"by sha", "cccccccccccc", "", []int{3}},
+		{"by abbreviated sha", "aaaaaaaaaaaa0123456789", "", []int{1}},
+		{"by branch", "", "feature", []int{1, 2}},
+		{"both narrow", "bbbbbbbbbbbb", "feature", []int{2}},
+		{"either", "cccccccccccc", "other", []int{3}},
+		{"none", "dddddddddddd", "main", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, pr := range matchPullRequests(prs, tt.sha, tt.branch) {
+				got = append(got, pr.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPullRequest(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	const openMRs = `[
+		{"iid": 3, "state": "opened", "source_branch": "feature", "sha": "abc123"},
+		{"iid": 4, "state": "opened", "source_branch": "feature", "sha": "def456"}
+	]`
+
+	tests := []struct {
+		name    string
+		sha     string
+		branch  string
+		wantErr string
+		wantURL string
+	}{
+		{"commit", "def456", "", "", "/api/v4/projects/owner/repo/merge_requests/4/notes"},
+		{"several", "", "feature", "several open pull requests found for branch feature (#3, #4)", ""},
+		{"none", "", "main", "no open pull request found for branch main", ""},
+		{"none falls back to the commit", "fff999", "", "", "/api/v4/projects/owner/repo/repository/commits/fff999/comments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commented string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/owner/repo/merge_requests":
+					_, _ = w.Write([]byte(openMRs))
+				case r.Method == http.MethodPost:
+					commented = r.URL.Path
+					_, _ = w.Write([]byte(`{"id": 1}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer srv.Close()
+
+			p, err := New(Config{
+				SCMProvider:  "gitlab",
+				SCMEndpoint:  srv.URL,
+				Token:        "token",
+				Repo:         "owner/repo",
+				CommitSHA:    tt.sha,
+				SourceBranch: tt.branch,
+				CommentBody:  "hello",
+			})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			err = p.Execute(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if commented != tt.wantURL {
+				t.Errorf("commented on %q, want %q", commented, tt.wantURL)
+			}
+		})
+	}
+}
@@ -2,11 +2,17 @@ package plugin
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/abhinav-harness/comment-plugin/internal/azure"
+	"github.com/abhinav-harness/comment-plugin/internal/bitbucket"
+	"github.com/abhinav-harness/comment-plugin/internal/bitbucketserver"
+	"github.com/abhinav-harness/comment-plugin/internal/gerrit"
+	"github.com/abhinav-harness/comment-plugin/internal/gitea"
+	"github.com/abhinav-harness/comment-plugin/internal/github"
+	"github.com/abhinav-harness/comment-plugin/internal/gitlab"
 	"github.com/abhinav-harness/comment-plugin/internal/harness"
 	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
 	"github.com/drone/go-scm/scm"
@@ -15,10 +21,25 @@ import (
 
 // Plugin represents the comment plugin
 type Plugin struct {
-	config  Config
-	client  *scm.Client
-	harness *harness.Client
-	log     *logrus.Entry
+	config   Config
+	provider scmclient.Provider
+	client   *scm.Client
+	pr       *scm.PullRequest
+	// decisionSubmitted is set once REVIEW_EVENT has been submitted
+	decisionSubmitted bool
+	// native is the provider's own client, behind the provider-neutral
+	// interfaces such as labeler and describer
+	native          interface{}
+	azure           *azure.Client
+	bitbucket       *bitbucket.Client
+	bitbucketServer *bitbucketserver.Client
+	github          *github.Client
+	gitea           *gitea.Client
+	gitlab          *gitlab.Client
+	gerrit          *gerrit.Client
+	gerritLabels    map[string]int
+	labelRules      []labelRule
+	log             *logrus.Entry
 }
 
 // New creates a new Plugin instance
@@ -27,34 +48,138 @@ func New(cfg Config) (*Plugin, error) {
 
 	provider := scmclient.Provider(strings.ToLower(cfg.SCMProvider))
 
-	client, err := scmclient.NewClient(scmclient.ClientOptions{
+	app, err := githubApp(cfg)
+	if err != nil {
+		return nil, err
+	}
+	oauth, err := oauth2Config(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" && app == nil && oauth == nil {
+		return nil, fmt.Errorf("TOKEN is required (or GITHUB_APP_ID or OAUTH_GRANT_TYPE to obtain tokens)")
+	}
+
+	if _, err := reviewEvent(cfg.ReviewEvent); err != nil {
+		return nil, err
+	}
+	labelRules, err := parseLabelRules(cfg.LabelRules)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CodeownersSeverity != "" && !validSeverity(cfg.CodeownersSeverity) {
+		return nil, fmt.Errorf("invalid CODEOWNERS_SEVERITY %q", cfg.CodeownersSeverity)
+	}
+	if _, _, err := descriptionMarkers(cfg); err != nil {
+		return nil, err
+	}
+	if !validPayloadKind(cfg.StatusPayloadKind) {
+		return nil, fmt.Errorf("invalid STATUS_PAYLOAD_KIND %q, expected markdown or raw", cfg.StatusPayloadKind)
+	}
+
+	// Checked here too because Gerrit never reaches the go-scm factory
+	authMode := scmclient.AuthMode(strings.ToLower(cfg.AuthMode))
+	err = scmclient.ValidateAuth(scmclient.ClientOptions{
 		Provider: provider,
-		Endpoint: cfg.SCMEndpoint,
-		Token:    cfg.Token,
+		Username: cfg.Username,
+		AuthMode: authMode,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create SCM client: %w", err)
+		return nil, err
+	}
+	bearer := authMode == scmclient.AuthBearer || authMode == scmclient.AuthOAuth2
+
+	// Providers without a go-scm driver only use their own client
+	var client *scm.Client
+	if !provider.NativeOnly() {
+		client, err = scmclient.NewClient(scmclient.ClientOptions{
+			Provider:          provider,
+			Endpoint:          cfg.SCMEndpoint,
+			Token:             cfg.Token,
+			Username:          cfg.Username,
+			AuthMode:          authMode,
+			HarnessAccountID:  cfg.HarnessAccountID,
+			HarnessOrgID:      cfg.HarnessOrgID,
+			HarnessProjectID:  cfg.HarnessProjectID,
+			AzureOrganization: cfg.AzureOrganization,
+			AzureProject:      cfg.AzureProject,
+			GitHubApp:         app,
+			OAuth2:            oauth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SCM client: %w", err)
+		}
 	}
 
 	p := &Plugin{
-		config: cfg,
-		client: client,
-		log:    log,
-	}
-
-	// Initialize Harness wrapper if using Harness Code
-	if provider == scmclient.ProviderHarness {
-		harnessClient, err := harness.NewClient(harness.Config{
-			Endpoint:  cfg.SCMEndpoint,
-			Token:     cfg.Token,
-			AccountID: cfg.HarnessAccountID,
-			OrgID:     cfg.HarnessOrgID,
-			ProjectID: cfg.HarnessProjectID,
+		config:     cfg,
+		provider:   provider,
+		client:     client,
+		labelRules: labelRules,
+		log:        log,
+	}
+
+	// Endpoints not covered by go-scm reuse its authenticated client
+	switch provider {
+	case scmclient.ProviderGitHub, scmclient.ProviderGitHubEnterprise:
+		p.github = github.NewClient(client)
+		p.native = p.github
+	case scmclient.ProviderHarness:
+		// The go-scm client is backed by the Harness Code client
+		p.native = harness.FromSCM(client)
+	case scmclient.ProviderGitea:
+		p.gitea = gitea.NewClient(client)
+		p.native = p.gitea
+	case scmclient.ProviderGitLab:
+		p.gitlab = gitlab.NewClient(client)
+		p.native = p.gitlab
+	case scmclient.ProviderBitbucket:
+		p.bitbucket = bitbucket.NewClient(client)
+		p.native = p.bitbucket
+	case scmclient.ProviderBitbucketServer:
+		p.bitbucketServer = bitbucketserver.NewClient(client)
+		p.native = p.bitbucketServer
+	}
+
+	// Initialize Azure DevOps client, go-scm has no PR comment or status support
+	if provider == scmclient.ProviderAzureDevOps {
+		azureClient, err := azure.NewClient(azure.Config{
+			Endpoint:     cfg.SCMEndpoint,
+			Token:        cfg.Token,
+			Username:     cfg.Username,
+			Bearer:       bearer,
+			Organization: cfg.AzureOrganization,
+			Project:      cfg.AzureProject,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create Harness client: %w", err)
+			return nil, fmt.Errorf("failed to create Azure DevOps client: %w", err)
 		}
-		p.harness = harnessClient
+		p.azure = azureClient
+		p.native = azureClient
+	}
+
+	// Initialize Gerrit client
+	if provider == scmclient.ProviderGerrit {
+		// Without a username the token is sent as a bearer token
+		username := cfg.Username
+		if bearer {
+			username = ""
+		}
+		gerritClient, err := gerrit.NewClient(gerrit.Config{
+			Endpoint: cfg.SCMEndpoint,
+			Username: username,
+			Token:    cfg.Token,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gerrit client: %w", err)
+		}
+		labels, err := parseLabels(cfg.GerritLabels)
+		if err != nil {
+			return nil, err
+		}
+		p.gerrit = gerritClient
+		p.native = gerritClient
+		p.gerritLabels = labels
 	}
 
 	return p, nil
@@ -64,28 +189,49 @@ func New(cfg Config) (*Plugin, error) {
 func (p *Plugin) Execute(ctx context.Context) error {
 	// Log all input configuration for debugging
 	p.log.WithFields(logrus.Fields{
-		"scm_provider":       p.config.SCMProvider,
-		"scm_endpoint":       p.config.SCMEndpoint,
-		"repo":               p.config.Repo,
-		"pr_number":          p.config.PRNumber,
-		"commit_sha":         p.config.CommitSHA,
-		"harness_account_id": p.config.HarnessAccountID,
-		"harness_org_id":     p.config.HarnessOrgID,
-		"harness_project_id": p.config.HarnessProjectID,
-		"comments_file":      p.config.CommentsFile,
-		"file_path":          p.config.FilePath,
-		"line":               p.config.Line,
-		"debug":              p.config.Debug,
-		"dry_run":            p.config.DryRun,
+		"scm_provider":         p.config.SCMProvider,
+		"scm_endpoint":         p.config.SCMEndpoint,
+		"repo":                 p.config.Repo,
+		"pr_number":            p.config.PRNumber,
+		"commit_sha":           p.config.CommitSHA,
+		"source_branch":        p.config.SourceBranch,
+		"harness_account_id":   p.config.HarnessAccountID,
+		"harness_org_id":       p.config.HarnessOrgID,
+		"harness_project_id":   p.config.HarnessProjectID,
+		"azure_organization":   p.config.AzureOrganization,
+		"azure_project":        p.config.AzureProject,
+		"comments_file":        p.config.CommentsFiles,
+		"file_path":            p.config.FilePath,
+		"line":                 p.config.Line,
+		"strict":               p.config.Strict,
+		"review_event":         p.config.ReviewEvent,
+		"checks":               p.config.Checks,
+		"labels_add":           p.config.LabelsAdd,
+		"labels_remove":        p.config.LabelsRemove,
+		"label_rules":          p.config.LabelRules,
+		"codeowners_reviewers": p.config.CodeownersReviewers,
+		"description_summary":  p.config.DescriptionSummary,
+		"debug":                p.config.Debug,
+		"dry_run":              p.config.DryRun,
+		"capabilities":         p.provider.Capabilities().List(),
 	}).Info("executing comment plugin with configuration")
 
+	p.logConfigDegradations()
+
 	if p.config.DryRun {
 		p.log.WithField("body", p.config.CommentBody).Info("dry run - would post comment")
 		return nil
 	}
 
+	// Find the pull request when only the commit or branch is known
+	if p.config.PRNumber == 0 && p.client != nil && p.needsPullRequest() && (p.config.CommitSHA != "" || p.config.SourceBranch != "") {
+		if err := p.findPullRequest(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Determine what action to take
-	if p.config.CommentsFile != "" {
+	if len(p.config.CommentsFiles) > 0 {
 		return p.createCommentsFromFile(ctx)
 	}
 
@@ -97,96 +243,216 @@ func (p *Plugin) Execute(ctx context.Context) error {
 		return p.createInlineComment(ctx)
 	}
 
+	if p.config.DescriptionBody != "" {
+		return p.updateDescription(ctx, p.config.DescriptionBody)
+	}
+
+	// The review carries COMMENT_BODY, so it is checked first
+	if p.config.ReviewEvent != "" {
+		return p.submitReviewDecision(ctx, nil)
+	}
+
 	if p.config.CommentBody != "" {
 		return p.createComment(ctx)
 	}
 
-	return fmt.Errorf("no action: provide COMMENT_BODY, FILE_PATH+LINE, COMMENTS_FILE, or STATUS_STATE")
+	if p.hasLabels() {
+		return p.updateLabels(ctx, nil, false)
+	}
+
+	return fmt.Errorf("no action: provide COMMENT_BODY, FILE_PATH+LINE, COMMENTS_FILE, STATUS_STATE, DESCRIPTION_BODY, REVIEW_EVENT or LABELS_ADD/LABELS_REMOVE")
 }
 
 func (p *Plugin) createCommentsFromFile(ctx context.Context) error {
-	if p.config.PRNumber == 0 {
-		return fmt.Errorf("PR_NUMBER is required")
+	files, err := expandCommentsFiles(p.config.CommentsFiles)
+	if err != nil {
+		return err
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(p.config.CommentsFile); os.IsNotExist(err) {
-		p.log.WithField("file", p.config.CommentsFile).Warn("comments file not found, skipping")
+	if len(files) == 0 {
+		p.log.WithField("patterns", p.config.CommentsFiles).Warn("no comments files matched, skipping")
+		if p.config.ReviewEvent != "" {
+			return p.submitReviewDecision(ctx, []ReviewComment{})
+		}
 		return nil
 	}
 
-	// Read the JSON file
-	data, err := os.ReadFile(p.config.CommentsFile)
+	reviews, err := loadReviews(files, p.config.Strict, p.log)
 	if err != nil {
-		return fmt.Errorf("failed to read comments file: %w", err)
+		return err
 	}
 
-	// Handle empty file
-	if len(data) == 0 {
-		p.log.WithField("file", p.config.CommentsFile).Warn("comments file is empty, skipping")
-		return nil
+	// Handle empty reviews array; label rules still clear and the
+	// description summary still updates
+	if len(reviews) == 0 {
+		p.log.WithField("files", files).Info("no reviews in files, nothing to post")
+		return p.afterReviews(ctx, reviews)
 	}
 
-	// Parse JSON
-	var reviewsFile ReviewsFile
-	if err := json.Unmarshal(data, &reviewsFile); err != nil {
-		return fmt.Errorf("failed to parse comments file: %w", err)
+	opts := renderOptions{
+		// Attribute each comment to its report when several were merged
+		Attribute:   len(files) > 1,
+		Suggestions: suggestionStyleFor(p.provider),
+		Ranges:      p.supports(scmclient.CapabilityRanges),
+		Scope:       p.threadScope(),
 	}
+	p.logReviewDegradations(reviews)
 
-	reviews := reviewsFile.Reviews
+	p.log.WithFields(logrus.Fields{
+		"files": len(files),
+		"count": len(reviews),
+	}).Info("merged reviews from comments files")
 
-	// Handle empty reviews array
-	if len(reviews) == 0 {
-		p.log.WithField("file", p.config.CommentsFile).Info("no reviews in file, nothing to post")
+	if err := p.postReviews(ctx, reviews, opts); err != nil {
+		return err
+	}
+	return p.afterReviews(ctx, reviews)
+}
+
+// afterReviews updates the labels, reviewers and description summary from
+// the findings of a comments file, and submits REVIEW_EVENT when no batch
+// review carried it
+func (p *Plugin) afterReviews(ctx context.Context, reviews []ReviewComment) error {
+	if err := p.updateLabels(ctx, reviews, true); err != nil {
+		return err
+	}
+	if err := p.requestCodeowners(ctx, reviews); err != nil {
+		return err
+	}
+	if err := p.updateDescriptionSummary(ctx, reviews); err != nil {
+		return err
+	}
+	if p.config.ReviewEvent != "" && !p.decisionSubmitted {
+		if p.config.PRNumber == 0 {
+			p.log.WithField("review_event", p.config.ReviewEvent).Warn("no pull request to review, ignoring review event")
+			return nil
+		}
+		if reviews == nil {
+			reviews = []ReviewComment{}
+		}
+		return p.submitReviewDecision(ctx, reviews)
+	}
+	return nil
+}
+
+// postReviews publishes the findings in the best form the provider supports
+func (p *Plugin) postReviews(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	if p.config.Checks && p.supports(scmclient.CapabilityChecks) {
+		switch {
+		case p.github != nil:
+			// GitHub check runs carry the findings as annotations instead of comments
+			return p.createCheckRunFromReviews(ctx, reviews, opts)
+
+		case p.reportChecker() != nil:
+			// Harness Code checks carry the full report alongside the comments
+			if err := p.createReportCheckFromReviews(ctx, reviews); err != nil {
+				return err
+			}
+
+		case p.bitbucket != nil || p.bitbucketServer != nil:
+			// Bitbucket Code Insights reports are published alongside the comments
+			if err := p.createInsightsReport(ctx, reviews, opts); err != nil {
+				return err
+			}
+		}
+		if p.config.PRNumber == 0 {
+			return nil
+		}
+	}
+
+	// Push builds comment on the commit itself
+	if p.commitComments() {
+		return p.createCommitComments(ctx, reviews, opts)
+	}
+
+	if p.config.PRNumber == 0 {
+		return fmt.Errorf("PR_NUMBER is required")
+	}
+
+	// Post the batch as one review where the provider supports it
+	if p.supports(scmclient.CapabilityBatchReview) {
+		err := p.submitReview(ctx, reviews, opts)
+		if err == nil {
+			p.log.WithField("count", len(reviews)).Info("submitted review with comments from file")
+			return nil
+		}
+		if !errors.Is(err, scm.ErrNotSupported) {
+			return err
+		}
+		p.log.WithError(err).Info("bulk review not supported, posting comments individually")
+	}
+
+	// Azure DevOps threads are anchored to the full line and column range
+	if p.azure != nil {
+		for i, review := range reviews {
+			_, err := p.azure.CreateThread(ctx, p.config.Repo, p.config.PRNumber, azure.ThreadInput{
+				Body:        formatReview(review, opts),
+				FilePath:    review.FilePath,
+				LineStart:   review.LineNumberStart,
+				LineEnd:     review.LineNumberEnd,
+				StartColumn: review.StartColumn,
+				EndColumn:   review.EndColumn,
+				Status:      p.config.AzureThreadStatus,
+			})
+			if err != nil {
+				p.log.WithError(err).WithField("index", i).WithField("path", review.FilePath).Warn("failed to create review thread")
+			}
+		}
+
+		p.log.WithField("count", len(reviews)).Info("finished creating review threads")
+		if p.config.AzureResolveFixed && p.supports(scmclient.CapabilityResolve) {
+			p.resolveFixedThreads(ctx, reviews)
+		}
 		return nil
 	}
 
-	p.log.WithFields(logrus.Fields{
-		"file":  p.config.CommentsFile,
-		"count": len(reviews),
-	}).Info("loaded reviews from file")
+	// Harness Code comments are anchored to the full line range
+	if commenter, ok := p.native.(rangeCommenter); ok {
+		return p.postRangeComments(ctx, commenter, reviews, opts)
+	}
+
+	// GitLab discussions need positions computed from the merge request diff
+	if p.gitlab != nil {
+		comments := make([]gitlab.ReviewComment, 0, len(reviews))
+		for _, review := range reviews {
+			comments = append(comments, gitlab.ReviewComment{
+				Path: review.FilePath,
+				Line: review.LineNumberEnd,
+				Body: formatReview(review, opts),
+			})
+		}
 
-	// Harness Code
-	if p.harness != nil {
-		// Get PR details to fetch commit SHAs
-		pr, err := p.getPRDetails(ctx)
+		created, err := p.gitlab.CreateDiscussions(ctx, p.config.Repo, p.config.PRNumber, comments)
 		if err != nil {
-			return fmt.Errorf("failed to get PR details: %w", err)
+			return err
 		}
 
-		// Create each review comment
+		p.log.WithField("count", created).Info("finished creating merge request discussions")
+		return nil
+	}
+
+	// Bitbucket inline comments are anchored to the full line range
+	if p.bitbucket != nil {
 		for i, review := range reviews {
-			err := p.harness.CreateReviewComment(
-				ctx,
-				p.config.Repo,
-				p.config.PRNumber,
-				review.FilePath,
-				review.LineNumberStart,
-				review.LineNumberEnd,
-				review.Type,
-				review.Review,
-				pr.SourceSHA,
-				pr.TargetSHA,
-			)
+			err := p.bitbucket.CreateInlineComment(ctx, p.config.Repo, p.config.PRNumber, bitbucket.InlineComment{
+				Path:      review.FilePath,
+				StartLine: review.LineNumberStart,
+				Line:      review.LineNumberEnd,
+				Body:      formatReview(review, opts),
+			})
 			if err != nil {
-				p.log.WithError(err).WithField("index", i).Warn("failed to create review comment")
-				// Continue with remaining comments
+				p.log.WithError(err).WithField("index", i).WithField("path", review.FilePath).Warn("failed to create review comment")
 			}
 		}
 
-		p.log.WithField("count", len(reviews)).Info("finished creating review comments")
+		p.log.WithField("count", len(reviews)).Info("finished creating review comments from file")
 		return nil
 	}
 
-	// For other SCM providers, use go-scm Reviews API
+	// Otherwise use go-scm Reviews API, one comment at a time
 	for i, review := range reviews {
-		commentText := review.Review
-		if review.Type != "" {
-			commentText = fmt.Sprintf("**%s:** %s", review.Type, review.Review)
-		}
-
 		input := &scm.ReviewInput{
-			Body: commentText,
+			Body: formatReview(review, opts),
 			Path: review.FilePath,
 			Line: review.LineNumberEnd,
 			Sha:  p.config.CommitSHA,
@@ -202,17 +468,26 @@ func (p *Plugin) createCommentsFromFile(ctx context.Context) error {
 	return nil
 }
 
-func (p *Plugin) getPRDetails(ctx context.Context) (*harness.PRDetails, error) {
-	// For Harness, we need to use the Harness client to get PR details
-	if p.harness != nil {
-		return p.harness.GetPRDetails(ctx, p.config.Repo, p.config.PRNumber)
+// pullRequest returns the PR_NUMBER pull request. It is fetched once per run
+// and shared by everything that needs its head, author or description.
+func (p *Plugin) pullRequest(ctx context.Context) (*scm.PullRequest, error) {
+	if p.pr != nil {
+		return p.pr, nil
 	}
 
-	// For other providers, we can use go-scm
 	pr, _, err := p.client.PullRequests.Find(ctx, p.config.Repo, p.config.PRNumber)
 	if err != nil {
 		return nil, err
 	}
+	p.pr = pr
+	return pr, nil
+}
+
+func (p *Plugin) getPRDetails(ctx context.Context) (*harness.PRDetails, error) {
+	pr, err := p.pullRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return &harness.PRDetails{
 		SourceSHA: pr.Sha,
@@ -221,13 +496,25 @@ func (p *Plugin) getPRDetails(ctx context.Context) (*harness.PRDetails, error) {
 }
 
 func (p *Plugin) createComment(ctx context.Context) error {
+	if p.commitComments() {
+		return p.createCommitComment(ctx, "", 0)
+	}
 	if p.config.PRNumber == 0 {
 		return fmt.Errorf("PR_NUMBER is required")
 	}
 
-	// Harness Code
-	if p.harness != nil {
-		return p.harness.CreateComment(ctx, p.config.Repo, p.config.PRNumber, p.config.CommentBody)
+	// Gerrit change message
+	if p.gerrit != nil {
+		return p.gerritReview(ctx, gerrit.ReviewInput{Message: p.config.CommentBody})
+	}
+
+	// Azure DevOps
+	if p.azure != nil {
+		_, err := p.azure.CreateThread(ctx, p.config.Repo, p.config.PRNumber, azure.ThreadInput{
+			Body:   p.config.CommentBody,
+			Status: p.config.AzureThreadStatus,
+		})
+		return err
 	}
 
 	// go-scm
@@ -242,16 +529,68 @@ func (p *Plugin) createComment(ctx context.Context) error {
 }
 
 func (p *Plugin) createInlineComment(ctx context.Context) error {
+	if p.config.CommentBody == "" {
+		return fmt.Errorf("COMMENT_BODY is required")
+	}
+	if p.commitComments() {
+		return p.createCommitComment(ctx, p.config.FilePath, p.config.Line)
+	}
 	if p.config.PRNumber == 0 {
 		return fmt.Errorf("PR_NUMBER is required")
 	}
-	if p.config.CommentBody == "" {
-		return fmt.Errorf("COMMENT_BODY is required")
+
+	// Azure DevOps
+	if p.azure != nil {
+		_, err := p.azure.CreateThread(ctx, p.config.Repo, p.config.PRNumber, azure.ThreadInput{
+			Body:      p.config.CommentBody,
+			FilePath:  p.config.FilePath,
+			LineStart: p.config.Line,
+			LineEnd:   p.config.Line,
+			Status:    p.config.AzureThreadStatus,
+		})
+		return err
 	}
 
-	// Harness Code
-	if p.harness != nil {
-		return p.harness.CreateInlineComment(ctx, p.config.Repo, p.config.PRNumber, p.config.FilePath, p.config.Line, p.config.CommentBody)
+	// Gerrit file comment
+	if p.gerrit != nil {
+		return p.gerritReview(ctx, gerrit.ReviewInput{
+			Comments: []gerrit.Comment{{
+				Path:       p.config.FilePath,
+				Line:       p.config.Line,
+				Message:    p.config.CommentBody,
+				Unresolved: true,
+			}},
+		})
+	}
+
+	// Bitbucket Cloud
+	if p.bitbucket != nil {
+		err := p.bitbucket.CreateInlineComment(ctx, p.config.Repo, p.config.PRNumber, bitbucket.InlineComment{
+			Path: p.config.FilePath,
+			Line: p.config.Line,
+			Body: p.config.CommentBody,
+		})
+		if err != nil {
+			return err
+		}
+
+		p.log.WithFields(logrus.Fields{"file": p.config.FilePath, "line": p.config.Line}).Info("created inline comment")
+		return nil
+	}
+
+	// GitLab
+	if p.gitlab != nil {
+		err := p.gitlab.CreateDiscussion(ctx, p.config.Repo, p.config.PRNumber, gitlab.ReviewComment{
+			Path: p.config.FilePath,
+			Line: p.config.Line,
+			Body: p.config.CommentBody,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create inline comment: %w", err)
+		}
+
+		p.log.WithFields(logrus.Fields{"file": p.config.FilePath, "line": p.config.Line}).Info("created inline comment")
+		return nil
 	}
 
 	// go-scm uses Reviews for inline comments
@@ -272,13 +611,40 @@ func (p *Plugin) createInlineComment(ctx context.Context) error {
 }
 
 func (p *Plugin) createStatus(ctx context.Context) error {
+	// Gerrit has no commit statuses; report on the change instead
+	if p.gerrit != nil {
+		return p.createGerritStatus(ctx)
+	}
+
+	// Azure DevOps can attach statuses to the pull request itself
+	if p.azure != nil {
+		input := azure.StatusInput{
+			State:       p.config.StatusState,
+			Context:     p.config.StatusContext,
+			Description: p.config.StatusDesc,
+			TargetURL:   p.config.StatusURL,
+		}
+		if p.config.PRNumber != 0 {
+			return p.azure.CreatePRStatus(ctx, p.config.Repo, p.config.PRNumber, input)
+		}
+		if p.config.CommitSHA != "" {
+			return p.azure.CreateCommitStatus(ctx, p.config.Repo, p.config.CommitSHA, input)
+		}
+		return fmt.Errorf("PR_NUMBER or COMMIT_SHA is required")
+	}
+
 	if p.config.CommitSHA == "" {
 		return fmt.Errorf("COMMIT_SHA is required")
 	}
 
-	// Harness Code
-	if p.harness != nil {
-		return p.harness.CreateStatus(ctx, p.config.Repo, p.config.CommitSHA, p.config.StatusState, p.config.StatusContext, p.config.StatusDesc, p.config.StatusURL)
+	// GitHub check run
+	if p.github != nil && p.config.Checks && p.supports(scmclient.CapabilityChecks) {
+		return p.createCheckRunStatus(ctx)
+	}
+
+	// Harness Code check with report and timing
+	if p.reportChecker() != nil {
+		return p.createReportStatus(ctx)
 	}
 
 	// go-scm
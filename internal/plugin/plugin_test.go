@@ -1,9 +1,15 @@
 package plugin
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
 	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
 )
 
 func TestMapStatusState(t *testing.T) {
@@ -22,3 +28,97 @@ func TestMapStatusState(t *testing.T) {
 		}
 	}
 }
+
+func TestPullRequestFetchedOnce(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	writeFile(t, "CODEOWNERS", "* @owner\n")
+	writeFile(t, "reviews.json", `{"reviews":[{"file_path":"a.go","line_number_start":1,"line_number_end":1,"review":"one"}]}`)
+
+	var finds int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api/v1/repos/owner/repo/pulls/2" {
+			finds++
+			_, _ = w.Write([]byte(`{"number": 2, "body": "", "user": {"login": "author"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider:         "gitea",
+		SCMEndpoint:         srv.URL,
+		Token:               "token",
+		Repo:                "owner/repo",
+		PRNumber:            2,
+		CommentsFiles:       []string{"reviews.json"},
+		CodeownersReviewers: true,
+		DescriptionSummary:  true,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if finds != 1 {
+		t.Errorf("pull request fetched %d times, want once", finds)
+	}
+}
+
+func TestNewValidatesAuth(t *testing.T) {
+	tests := []Config{
+		{SCMProvider: "gerrit", SCMEndpoint: "https://review.example.com", AuthMode: "token"},
+		{SCMProvider: "harness", AuthMode: "basic", Username: "user"},
+		{SCMProvider: "azure-devops", AzureOrganization: "org", AzureProject: "proj", AuthMode: "basic"},
+	}
+
+	for _, cfg := range tests {
+		cfg.Token, cfg.Repo = "token", "repo"
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New should reject auth mode %q with username %q on %s", cfg.AuthMode, cfg.Username, cfg.SCMProvider)
+		}
+	}
+}
+
+func TestNativeClientInterfaces(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	for _, provider := range scmclient.SupportedProviders() {
+		p, err := New(Config{
+			SCMProvider:       string(provider),
+			SCMEndpoint:       "https://scm.example.com",
+			Token:             "token",
+			Repo:              "org/repo",
+			AzureOrganization: "org",
+			AzureProject:      "proj",
+		})
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", provider, err)
+		}
+
+		// Each capability is backed by the provider's own client
+		capabilities := map[scmclient.Capability]bool{
+			scmclient.CapabilityLabels:         p.labelClient() != nil,
+			scmclient.CapabilityDescription:    p.descriptionClient() != nil,
+			scmclient.CapabilityReviewers:      p.reviewerClient() != nil,
+			scmclient.CapabilityCommitComments: p.commitClient() != nil,
+		}
+		_, capabilities[scmclient.CapabilityReviewState] = p.native.(reviewDecider)
+		for capability, implemented := range capabilities {
+			if implemented != p.supports(capability) {
+				t.Errorf("%s: %s implemented = %v, supported = %v", provider, capability, implemented, p.supports(capability))
+			}
+		}
+	}
+}
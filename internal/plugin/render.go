@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+)
+
+// suggestionStyle is how a provider expresses a suggested replacement
+type suggestionStyle int
+
+const (
+	suggestionFenced suggestionStyle = iota // plain code block, no apply button
+	suggestionGitHub                        // ```suggestion, replaces the commented lines
+	suggestionGitLab                        // ```suggestion:-N+0, relative to the commented line
+	suggestionNone                          // sent outside the body, e.g. Gerrit fix suggestions
+)
+
+func suggestionStyleFor(provider scmclient.Provider) suggestionStyle {
+	if !provider.Capabilities().Has(scmclient.CapabilitySuggestions) {
+		return suggestionFenced
+	}
+
+	switch provider {
+	case scmclient.ProviderGitHub, scmclient.ProviderGitHubEnterprise, scmclient.ProviderHarness:
+		return suggestionGitHub
+	case scmclient.ProviderGitLab:
+		return suggestionGitLab
+	case scmclient.ProviderGerrit:
+		return suggestionNone
+	default:
+		return suggestionFenced
+	}
+}
+
+// renderOptions control how a review is rendered for a provider
+type renderOptions struct {
+	// Attribute appends the source file (set when several files were merged)
+	Attribute bool
+	// Suggestions is the provider's suggestion syntax
+	Suggestions suggestionStyle
+	// Ranges is true when the comment is anchored to the full line range
+	// rather than only line_number_end
+	Ranges bool
+	// Scope identifies the step in the fingerprint marker, so only its own
+	// threads are resolved
+	Scope string
+}
+
+// formatReview formats a review with its type as a bold prefix.
+func formatReview(review ReviewComment, opts renderOptions) string {
+	body := reviewBody(review, opts)
+	if review.Type != "" {
+		return fmt.Sprintf("**%s:** %s", review.Type, body)
+	}
+	return body
+}
+
+// reviewBody renders the review text followed by whatever metadata, suggested
+// change and attribution the review carries. The type prefix is not included.
+func reviewBody(review ReviewComment, opts renderOptions) string {
+	parts := []string{review.Review}
+
+	if details := reviewDetails(review); details != "" {
+		parts = append(parts, details)
+	}
+
+	if review.Suggestion != "" && opts.Suggestions != suggestionNone {
+		parts = append(parts, suggestionBlock(review, opts))
+	}
+
+	if opts.Attribute && review.Source != "" {
+		parts = append(parts, fmt.Sprintf("_Source: `%s`_", review.Source))
+	}
+
+	if review.Fingerprint != "" {
+		parts = append(parts, fingerprintMarker(review.Fingerprint, opts.Scope))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+func reviewDetails(review ReviewComment) string {
+	var details []string
+
+	if review.Severity != "" {
+		severity := severityOf(review)
+		details = append(details, fmt.Sprintf("Severity: %s %s", severityEmoji(severity), severity))
+	}
+
+	switch {
+	case review.RuleID != "" && review.DocumentationURL != "":
+		details = append(details, fmt.Sprintf("Rule: [`%s`](%s)", review.RuleID, review.DocumentationURL))
+	case review.RuleID != "":
+		details = append(details, fmt.Sprintf("Rule: `%s`", review.RuleID))
+	case review.DocumentationURL != "":
+		details = append(details, fmt.Sprintf("[Documentation](%s)", review.DocumentationURL))
+	}
+
+	if review.Confidence > 0 {
+		details = append(details, fmt.Sprintf("Confidence: %.0f%%", review.Confidence*100))
+	}
+
+	if len(review.Tags) > 0 {
+		tags := make([]string, len(review.Tags))
+		for i, tag := range review.Tags {
+			tags[i] = "`" + tag + "`"
+		}
+		details = append(details, "Tags: "+strings.Join(tags, ", "))
+	}
+
+	if len(details) == 0 {
+		return ""
+	}
+	return "_" + strings.Join(details, " · ") + "_"
+}
+
+func suggestionBlock(review ReviewComment, opts renderOptions) string {
+	suggestion := strings.TrimSuffix(review.Suggestion, "\n")
+	multiLine := review.LineNumberEnd > review.LineNumberStart
+
+	switch {
+	case opts.Suggestions == suggestionGitLab:
+		lines := 0
+		if multiLine {
+			lines = review.LineNumberEnd - review.LineNumberStart
+		}
+		return fmt.Sprintf("```suggestion:-%d+0\n%s\n```", lines, suggestion)
+	case opts.Suggestions == suggestionGitHub && (opts.Ranges || !multiLine):
+		return fmt.Sprintf("```suggestion\n%s\n```", suggestion)
+	default:
+		// The provider cannot apply the change to the commented lines
+		return fmt.Sprintf("Suggested change (lines %d-%d):\n```\n%s\n```", review.LineNumberStart, review.LineNumberEnd, suggestion)
+	}
+}
+
+func fingerprintMarker(fingerprint, scope string) string {
+	if scope == "" {
+		return fmt.Sprintf("<!-- comment-plugin:fingerprint=%s -->", fingerprint)
+	}
+	return fmt.Sprintf("<!-- comment-plugin:fingerprint=%s scope=%s -->", fingerprint, scope)
+}
+
+// fingerprintPattern finds the fingerprint marker in a posted comment
+var fingerprintPattern = regexp.MustCompile(`<!-- comment-plugin:fingerprint=(\S+)(?: scope=(\S+))? -->`)
+
+// markedFingerprint returns the fingerprint and scope of a comment posted by
+// the plugin; the fingerprint is "" when the comment has no marker
+func markedFingerprint(body string) (fingerprint, scope string) {
+	if m := fingerprintPattern.FindStringSubmatch(body); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
+
+// reviewFingerprint derives a stable fingerprint for a review from its path,
+// rule (or type) and normalized text. Line numbers are deliberately excluded
+// so the fingerprint survives unrelated edits that shift the code.
+func reviewFingerprint(review ReviewComment) string {
+	rule := review.RuleID
+	if rule == "" {
+		rule = review.Type
+	}
+	text := strings.Join(strings.Fields(review.Review), " ")
+
+	sum := sha256.Sum256([]byte(review.FilePath + "\x00" + rule + "\x00" + text))
+	return hex.EncodeToString(sum[:8])
+}
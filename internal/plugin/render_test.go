@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatReviewVersion1(t *testing.T) {
+	review := ReviewComment{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Type: "bug", Review: "one", Source: "first.json"}
+
+	if got := formatReview(review, renderOptions{}); got != "**bug:** one" {
+		t.Errorf("formatReview = %q, want %q", got, "**bug:** one")
+	}
+
+	want := "**bug:** one\n\n_Source: `first.json`_"
+	if got := formatReview(review, renderOptions{Attribute: true}); got != want {
+		t.Errorf("formatReview with attribution = %q, want %q", got, want)
+	}
+}
+
+func TestFormatReviewVersion2(t *testing.T) {
+	review := ReviewComment{
+		FilePath:         "a.go",
+		LineNumberStart:  10,
+		LineNumberEnd:    12,
+		Type:             "bug",
+		Review:           "Possible nil dereference",
+		Severity:         "high",
+		RuleID:           "nil-deref",
+		DocumentationURL: "https://example.com/nil-deref",
+		Suggestion:       "if x != nil {\n\tx.Do()\n}",
+		Confidence:       0.85,
+		Tags:             []string{"safety"},
+		Fingerprint:      "abc123",
+	}
+
+	got := formatReview(review, renderOptions{Suggestions: suggestionGitHub, Ranges: true})
+	for _, want := range []string{
+		"**bug:** Possible nil dereference",
+		"Severity: 🟠 high",
+		"Rule: [`nil-deref`](https://example.com/nil-deref)",
+		"Confidence: 85%",
+		"Tags: `safety`",
+		"```suggestion\nif x != nil {",
+		"<!-- comment-plugin:fingerprint=abc123 -->",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatReview output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSuggestionBlock(t *testing.T) {
+	review := ReviewComment{LineNumberStart: 3, LineNumberEnd: 5, Suggestion: "new\n"}
+
+	tests := []struct {
+		opts renderOptions
+		want string
+	}{
+		{renderOptions{Suggestions: suggestionGitHub, Ranges: true}, "```suggestion\nnew\n```"},
+		{renderOptions{Suggestions: suggestionGitHub}, "Suggested change (lines 3-5):\n```\nnew\n```"},
+		{renderOptions{Suggestions: suggestionGitLab}, "```suggestion:-2+0\nnew\n```"},
+		{renderOptions{Suggestions: suggestionFenced}, "Suggested change (lines 3-5):\n```\nnew\n```"},
+	}
+
+	for _, tt := range tests {
+		if got := suggestionBlock(review, tt.opts); got != tt.want {
+			t.Errorf("suggestionBlock(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestReviewFingerprintIgnoresLines(t *testing.T) {
+	a := ReviewComment{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Type: "bug", Review: "same  text"}
+	b := ReviewComment{FilePath: "a.go", LineNumberStart: 9, LineNumberEnd: 9, Type: "bug", Review: "same text"}
+	c := ReviewComment{FilePath: "b.go", LineNumberStart: 1, LineNumberEnd: 1, Type: "bug", Review: "same text"}
+
+	if reviewFingerprint(a) != reviewFingerprint(b) {
+		t.Error("fingerprint should not depend on line numbers or whitespace")
+	}
+	if reviewFingerprint(a) == reviewFingerprint(c) {
+		t.Error("fingerprint should depend on the file path")
+	}
+}
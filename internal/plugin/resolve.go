@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Azure DevOps thread statuses of findings that are still open
+var openThreadStatuses = map[string]bool{
+	"active":  true,
+	"pending": true,
+}
+
+// threadScope identifies the step posting the findings in their fingerprint
+// markers: STATUS_CONTEXT when set, otherwise the COMMENTS_FILE entries. Two
+// steps on one pull request, such as a SARIF log and a gitleaks report, then
+// only resolve their own threads.
+func (p *Plugin) threadScope() string {
+	scope := p.config.StatusContext
+	if scope == "" {
+		scope = strings.Join(p.config.CommentsFiles, ",")
+	}
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:4])
+}
+
+// resolveFixedThreads marks the open Azure DevOps threads of earlier findings
+// that the batch no longer reports as fixed. Only threads whose fingerprint
+// marker has this step's scope are considered; threads of other steps, of
+// people, and of version 1 comments, which have no marker, are left alone.
+// Failures are logged, as the findings themselves are already posted.
+func (p *Plugin) resolveFixedThreads(ctx context.Context, reviews []ReviewComment) {
+	current := make(map[string]bool, len(reviews))
+	var unmarked int
+	for _, review := range reviews {
+		if review.Fingerprint == "" {
+			unmarked++
+			continue
+		}
+		current[review.Fingerprint] = true
+	}
+	if unmarked > 0 {
+		p.log.WithField("count", unmarked).Warn("version 1 comments have no fingerprint marker, their threads cannot be resolved")
+	}
+
+	threads, err := p.azure.ListThreads(ctx, p.config.Repo, p.config.PRNumber)
+	if err != nil {
+		p.log.WithError(err).Warn("failed to list review threads, not resolving fixed findings")
+		return
+	}
+
+	scope := p.threadScope()
+	var resolved int
+	for _, thread := range threads {
+		fingerprint, threadScope := markedFingerprint(thread.Content)
+		if fingerprint == "" || threadScope != scope || current[fingerprint] || !openThreadStatuses[thread.Status] {
+			continue
+		}
+		if err := p.azure.UpdateThreadStatus(ctx, p.config.Repo, p.config.PRNumber, thread.ID, "fixed"); err != nil {
+			p.log.WithError(err).WithField("thread_id", thread.ID).Warn("failed to resolve review thread")
+			continue
+		}
+		resolved++
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"threads":  len(threads),
+		"resolved": resolved,
+	}).Info("resolved threads of fixed findings")
+}
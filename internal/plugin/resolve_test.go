@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMarkedFingerprint(t *testing.T) {
+	body := "Fix this\n\n" + fingerprintMarker("abc123", "")
+	if got, scope := markedFingerprint(body); got != "abc123" || scope != "" {
+		t.Errorf("markedFingerprint = %q, %q, want abc123 without a scope", got, scope)
+	}
+	body = "Fix this\n\n" + fingerprintMarker("abc123", "0f1e2d3c")
+	if got, scope := markedFingerprint(body); got != "abc123" || scope != "0f1e2d3c" {
+		t.Errorf("markedFingerprint = %q, %q, want abc123 in scope 0f1e2d3c", got, scope)
+	}
+	if got, _ := markedFingerprint("plain comment"); got != "" {
+		t.Errorf("markedFingerprint of an unmarked comment = %q", got)
+	}
+}
+
+func TestAzureResolveFixedThreads(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	writeFile(t, "reviews.json", `{"version":2,"reviews":[{"file_path":"a.go","line_number_start":1,"line_number_end":1,"review":"still here","fingerprint":"kept"}]}`)
+
+	// Threads of this step, of another step on the same pull request, and of
+	// a person
+	var threads string
+	var resolved []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pullRequests/7/threads"):
+			_, _ = w.Write([]byte(threads))
+		case r.Method == http.MethodPatch:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			resolved = append(resolved, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]+"="+body["status"])
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": 5}`))
+		}
+	}))
+	defer srv.Close()
+
+	newPlugin := func(statusContext string) *Plugin {
+		p, err := New(Config{
+			SCMProvider:       "azure-devops",
+			SCMEndpoint:       srv.URL,
+			Token:             "pat",
+			Repo:              "my-repo",
+			PRNumber:          7,
+			AzureOrganization: "org",
+			AzureProject:      "proj",
+			CommentsFiles:     []string{"reviews.json"},
+			StatusContext:     statusContext,
+			AzureResolveFixed: true,
+		})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		return p
+	}
+	sarif, gitleaks := newPlugin("sarif"), newPlugin("gitleaks")
+
+	thread := func(id int, status, fingerprint, scope string) string {
+		content, _ := json.Marshal("old " + fingerprintMarker(fingerprint, scope))
+		return fmt.Sprintf(`{"id": %d, "status": %q, "comments": [{"content": %s}]}`, id, status, content)
+	}
+	threads = `{"value": [` + strings.Join([]string{
+		thread(1, "active", "kept", sarif.threadScope()),
+		thread(2, "active", "gone", sarif.threadScope()),
+		thread(3, "fixed", "done", sarif.threadScope()),
+		thread(4, "active", "leak", gitleaks.threadScope()),
+		thread(6, "active", "legacy", ""),
+		`{"id": 7, "status": "active", "comments": [{"content": "written by a person"}]}`,
+	}, ", ") + `]}`
+
+	if err := sarif.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0] != "2=fixed" {
+		t.Errorf("resolved threads = %v, want [2=fixed]", resolved)
+	}
+
+	resolved = nil
+	if err := gitleaks.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0] != "4=fixed" {
+		t.Errorf("resolved threads of the second step = %v, want [4=fixed]", resolved)
+	}
+}
@@ -0,0 +1,298 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abhinav-harness/comment-plugin/internal/gitea"
+	"github.com/abhinav-harness/comment-plugin/internal/github"
+	"github.com/abhinav-harness/comment-plugin/internal/gitlab"
+	scmclient "github.com/abhinav-harness/comment-plugin/internal/scm"
+	"github.com/drone/go-scm/scm"
+)
+
+// Review states accepted by REVIEW_EVENT
+const (
+	reviewEventComment        = "comment"
+	reviewEventApprove        = "approve"
+	reviewEventRequestChanges = "request_changes"
+	// reviewEventAuto requests changes when a finding is critical or high and
+	// approves otherwise
+	reviewEventAuto = "auto"
+)
+
+// GitHub and Gitea name the approving event differently
+var (
+	githubReviewEvents = map[string]string{
+		reviewEventComment:        "COMMENT",
+		reviewEventApprove:        "APPROVE",
+		reviewEventRequestChanges: "REQUEST_CHANGES",
+	}
+	giteaReviewEvents = map[string]string{
+		reviewEventComment:        "COMMENT",
+		reviewEventApprove:        "APPROVED",
+		reviewEventRequestChanges: "REQUEST_CHANGES",
+	}
+	harnessReviewDecisions = map[string]string{
+		reviewEventComment:        "reviewed",
+		reviewEventApprove:        "approved",
+		reviewEventRequestChanges: "changereq",
+	}
+
+	// reviewEvents are the names of each provider with the review state
+	// capability
+	reviewEvents = map[scmclient.Provider]map[string]string{
+		scmclient.ProviderGitHub:           githubReviewEvents,
+		scmclient.ProviderGitHubEnterprise: githubReviewEvents,
+		scmclient.ProviderGitea:            giteaReviewEvents,
+		scmclient.ProviderHarness:          harnessReviewDecisions,
+	}
+)
+
+// reviewDecider submits a review state with a body, without inline comments,
+// on one provider. commitSHA may be empty for the pull request head.
+type reviewDecider interface {
+	SubmitReview(ctx context.Context, repo string, prNumber int, commitSHA, event, body string) error
+}
+
+// rangeCommenter posts a code comment anchored to a line range between the
+// pull request's source commit and merge base, as Harness Code does
+type rangeCommenter interface {
+	CreateReviewComment(ctx context.Context, repo string, prNumber int, filePath string, lineStart, lineEnd int, reviewType, reviewText, sourceSHA, targetSHA string) (int, error)
+}
+
+// reviewEvent returns the normalized REVIEW_EVENT, defaulting to comment
+func reviewEvent(event string) (string, error) {
+	event = strings.ToLower(strings.TrimSpace(event))
+	if event == "" {
+		return reviewEventComment, nil
+	}
+	if _, ok := githubReviewEvents[event]; !ok && event != reviewEventAuto {
+		return "", fmt.Errorf("invalid REVIEW_EVENT %q, expected comment, approve, request_changes or auto", event)
+	}
+	return event, nil
+}
+
+// decideReviewEvent resolves the auto review event from the findings; other
+// events are returned unchanged
+func decideReviewEvent(event string, reviews []ReviewComment) string {
+	if event != reviewEventAuto {
+		return event
+	}
+	if checkConclusion(reviews) == "failure" {
+		return reviewEventRequestChanges
+	}
+	return reviewEventApprove
+}
+
+// submitReviewDecision submits REVIEW_EVENT on its own, for runs where it
+// was not carried by a batch review: GitHub and Gitea get a review without
+// comments, Harness Code a review decision on the pull request head, so the
+// step can satisfy or block approval rules. reviews are the batch findings
+// used by the auto event; nil when there is no batch. Providers without
+// review states get COMMENT_BODY as a plain comment instead.
+func (p *Plugin) submitReviewDecision(ctx context.Context, reviews []ReviewComment) error {
+	if !p.supports(scmclient.CapabilityReviewState) {
+		// Already reported by logConfigDegradations
+		if reviews != nil {
+			return nil
+		}
+		if p.config.CommentBody != "" {
+			return p.createComment(ctx)
+		}
+		return fmt.Errorf("REVIEW_EVENT is not supported on %s", p.provider)
+	}
+	if p.config.PRNumber == 0 {
+		return fmt.Errorf("PR_NUMBER is required")
+	}
+
+	event, err := reviewEvent(p.config.ReviewEvent)
+	if err != nil {
+		return err
+	}
+	if event == reviewEventAuto && reviews == nil {
+		return fmt.Errorf("REVIEW_EVENT auto requires COMMENTS_FILE")
+	}
+	event = decideReviewEvent(event, reviews)
+
+	// GitHub rejects a review without a body unless it approves
+	body := p.config.CommentBody
+	if body == "" && event != reviewEventApprove && reviews != nil {
+		body = summaryMarkdown(reviews)
+	}
+
+	decider, ok := p.native.(reviewDecider)
+	if !ok {
+		return fmt.Errorf("REVIEW_EVENT is not supported on %s", p.provider)
+	}
+	err = decider.SubmitReview(ctx, p.config.Repo, p.config.PRNumber, p.config.CommitSHA, reviewEvents[p.provider][event], body)
+	if err != nil {
+		return err
+	}
+
+	p.decisionSubmitted = true
+	p.log.WithField("review_event", event).Info("submitted review decision")
+	return nil
+}
+
+// reviewBodyText is the overall body of a submitted review: COMMENT_BODY when
+// set, otherwise a summary of the findings.
+func (p *Plugin) reviewBodyText(reviews []ReviewComment) string {
+	if p.config.CommentBody != "" {
+		return p.config.CommentBody
+	}
+	return summaryMarkdown(reviews)
+}
+
+// submitReview posts the whole batch as a single review, for providers with
+// the batch review capability. The returned error wraps scm.ErrNotSupported
+// when the review could not be published, in which case the caller posts the
+// comments one by one.
+func (p *Plugin) submitReview(ctx context.Context, reviews []ReviewComment, opts renderOptions) error {
+	body := p.reviewBodyText(reviews)
+
+	event, err := reviewEvent(p.config.ReviewEvent)
+	if err != nil {
+		return err
+	}
+	event = decideReviewEvent(event, reviews)
+
+	switch {
+	case p.github != nil:
+		comments := make([]github.ReviewComment, 0, len(reviews))
+		for _, review := range reviews {
+			comments = append(comments, github.ReviewComment{
+				Path:      review.FilePath,
+				StartLine: review.LineNumberStart,
+				Line:      review.LineNumberEnd,
+				Body:      formatReview(review, opts),
+			})
+		}
+		input := github.ReviewInput{
+			CommitSHA: p.config.CommitSHA,
+			Body:      body,
+			Event:     githubReviewEvents[event],
+			Comments:  comments,
+		}
+		err := p.github.CreateReview(ctx, p.config.Repo, p.config.PRNumber, input)
+		err = p.retryRejectedReview(err, len(comments), func(i int) error {
+			return p.github.CreateReview(ctx, p.config.Repo, p.config.PRNumber, github.ReviewInput{
+				CommitSHA: p.config.CommitSHA,
+				Event:     githubReviewEvents[reviewEventComment],
+				Comments:  comments[i : i+1],
+			})
+		}, func() error {
+			input.Comments = nil
+			return p.github.CreateReview(ctx, p.config.Repo, p.config.PRNumber, input)
+		})
+		p.decisionSubmitted = err == nil
+		return err
+
+	case p.gitea != nil:
+		comments := make([]gitea.ReviewComment, 0, len(reviews))
+		for _, review := range reviews {
+			comments = append(comments, gitea.ReviewComment{
+				Path: review.FilePath,
+				Line: review.LineNumberEnd,
+				Body: formatReview(review, opts),
+			})
+		}
+		input := gitea.ReviewInput{
+			CommitSHA: p.config.CommitSHA,
+			Body:      body,
+			Event:     giteaReviewEvents[event],
+			Comments:  comments,
+		}
+		err := p.gitea.CreateReview(ctx, p.config.Repo, p.config.PRNumber, input)
+		err = p.retryRejectedReview(err, len(comments), func(i int) error {
+			return p.gitea.CreateReview(ctx, p.config.Repo, p.config.PRNumber, gitea.ReviewInput{
+				CommitSHA: p.config.CommitSHA,
+				Event:     giteaReviewEvents[reviewEventComment],
+				Comments:  comments[i : i+1],
+			})
+		}, func() error {
+			input.Comments = nil
+			return p.gitea.CreateReview(ctx, p.config.Repo, p.config.PRNumber, input)
+		})
+		p.decisionSubmitted = err == nil
+		return err
+
+	case p.gerrit != nil:
+		return p.createGerritReviewFromReviews(ctx, reviews, opts)
+
+	case p.gitlab != nil:
+		comments := make([]gitlab.ReviewComment, 0, len(reviews))
+		for _, review := range reviews {
+			comments = append(comments, gitlab.ReviewComment{
+				Path: review.FilePath,
+				Line: review.LineNumberEnd,
+				Body: formatReview(review, opts),
+			})
+		}
+		err := p.gitlab.CreateReview(ctx, p.config.Repo, p.config.PRNumber, gitlab.ReviewInput{
+			Body:     body,
+			Comments: comments,
+		})
+		// The drafts were deleted, so the comments can be posted as discussions
+		if errors.Is(err, gitlab.ErrNotPublished) {
+			return fmt.Errorf("%w: %v", scm.ErrNotSupported, err)
+		}
+		return err
+	}
+
+	return scm.ErrNotSupported
+}
+
+// retryRejectedReview handles a batch review the provider refused because of
+// its content, typically a comment on a line outside the diff. Each comment is
+// then posted as a review of its own, so one bad anchor does not drop the
+// rest, and submit posts the review body and event without comments.
+func (p *Plugin) retryRejectedReview(err error, count int, post func(i int) error, submit func() error) error {
+	if !scmclient.Rejected(err) {
+		return err
+	}
+	p.log.WithError(err).Warn("review rejected, posting comments one by one")
+
+	for i := 0; i < count; i++ {
+		if err := post(i); err != nil {
+			p.log.WithError(err).WithField("index", i).Warn("failed to create review comment")
+		}
+	}
+	return submit()
+}
+
+// postRangeComments posts each finding as a code comment anchored to its full
+// line range, then records the review decision when REVIEW_EVENT is set.
+// There is no bulk review API, so a failed comment is logged and the rest are
+// still posted.
+func (p *Plugin) postRangeComments(ctx context.Context, commenter rangeCommenter, reviews []ReviewComment, opts renderOptions) error {
+	pr, err := p.getPRDetails(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get PR details: %w", err)
+	}
+
+	for i, review := range reviews {
+		_, err := commenter.CreateReviewComment(
+			ctx,
+			p.config.Repo,
+			p.config.PRNumber,
+			review.FilePath,
+			review.LineNumberStart,
+			review.LineNumberEnd,
+			review.Type,
+			reviewBody(review, opts),
+			pr.SourceSHA,
+			pr.TargetSHA,
+		)
+		if err != nil {
+			p.log.WithError(err).WithField("index", i).Warn("failed to create review comment")
+		}
+	}
+
+	if p.config.ReviewEvent != "" {
+		return p.submitReviewDecision(ctx, reviews)
+	}
+	return nil
+}
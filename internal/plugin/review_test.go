@@ -0,0 +1,402 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestReviewEvent(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", reviewEventComment, false},
+		{"approve", reviewEventApprove, false},
+		{" Request_Changes ", reviewEventRequestChanges, false},
+		{"approved", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := reviewEvent(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("reviewEvent(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("reviewEvent(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSubmitReviewGiteaEvent(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/pulls/2/reviews" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider: "gitea",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "owner/repo",
+		PRNumber:    2,
+		ReviewEvent: "approve",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	reviews := []ReviewComment{{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Type: "style", Review: "nit"}}
+	if err := p.submitReview(context.Background(), reviews, renderOptions{}); err != nil {
+		t.Fatalf("submitReview failed: %v", err)
+	}
+	if body["event"] != "APPROVED" {
+		t.Errorf("event = %v, want APPROVED", body["event"])
+	}
+}
+
+func TestSubmitReviewRetriesRejectedComments(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var reviews []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		comments, _ := body["comments"].([]interface{})
+		for _, comment := range comments {
+			if comment.(map[string]interface{})["path"] == "gone.go" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		reviews = append(reviews, body)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider: "gitea",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "owner/repo",
+		PRNumber:    2,
+		ReviewEvent: "request_changes",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	findings := []ReviewComment{
+		{FilePath: "a.go", LineNumberStart: 1, LineNumberEnd: 1, Review: "nit"},
+		{FilePath: "gone.go", LineNumberStart: 9, LineNumberEnd: 9, Review: "outside the diff"},
+		{FilePath: "b.go", LineNumberStart: 3, LineNumberEnd: 3, Review: "bug"},
+	}
+	if err := p.submitReview(context.Background(), findings, renderOptions{}); err != nil {
+		t.Fatalf("submitReview failed: %v", err)
+	}
+
+	if len(reviews) != 3 {
+		t.Fatalf("got %d reviews, want both valid comments and the decision", len(reviews))
+	}
+	if reviews[0]["event"] != "COMMENT" || reviews[1]["event"] != "COMMENT" {
+		t.Errorf("single comments should not carry the decision: %v", reviews[:2])
+	}
+	if last := reviews[2]; last["event"] != "REQUEST_CHANGES" || len(last["comments"].([]interface{})) != 0 {
+		t.Errorf("unexpected decision review %v", last)
+	}
+}
+
+// newGiteaReviewServer records the reviews posted to pull request 2
+func newGiteaReviewServer(t *testing.T) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+
+	var reviews []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/pulls/2/reviews" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		reviews = append(reviews, body)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reviews
+}
+
+func TestReviewDecisionWithoutFindings(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	writeFile(t, empty, `{"reviews": []}`)
+
+	tests := []struct {
+		name  string
+		files []string
+		event string
+		want  string
+	}{
+		{"empty batch with auto", []string{empty}, "auto", "APPROVED"},
+		{"no matching file", []string{filepath.Join(dir, "missing-*.json")}, "approve", "APPROVED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reviews := newGiteaReviewServer(t)
+
+			p, err := New(Config{
+				SCMProvider:   "gitea",
+				SCMEndpoint:   srv.URL,
+				Token:         "token",
+				Repo:          "owner/repo",
+				PRNumber:      2,
+				CommentsFiles: tt.files,
+				ReviewEvent:   tt.event,
+			})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			if err := p.Execute(context.Background()); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if len(*reviews) != 1 {
+				t.Fatalf("got %d reviews, want 1", len(*reviews))
+			}
+			review := (*reviews)[0]
+			if review["event"] != tt.want || review["body"] != "" || len(review["comments"].([]interface{})) != 0 {
+				t.Errorf("unexpected review %v", review)
+			}
+		})
+	}
+}
+
+func TestReviewDecisionSubmittedOnce(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	file := filepath.Join(t.TempDir(), "reviews.json")
+	writeFile(t, file, `{"reviews": [{"file_path": "a.go", "line_number_start": 1, "line_number_end": 1, "type": "bug", "review": "fix"}]}`)
+
+	srv, reviews := newGiteaReviewServer(t)
+	p, err := New(Config{
+		SCMProvider:   "gitea",
+		SCMEndpoint:   srv.URL,
+		Token:         "token",
+		Repo:          "owner/repo",
+		PRNumber:      2,
+		CommentsFiles: []string{file},
+		ReviewEvent:   "request_changes",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(*reviews) != 1 || (*reviews)[0]["event"] != "REQUEST_CHANGES" {
+		t.Errorf("the batch review should carry the decision: %v", *reviews)
+	}
+}
+
+func TestReviewDecisionWithCommentBody(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	srv, reviews := newGiteaReviewServer(t)
+	p, err := New(Config{
+		SCMProvider: "gitea",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "owner/repo",
+		PRNumber:    2,
+		CommentBody: "Looks good",
+		ReviewEvent: "approve",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(*reviews) != 1 || (*reviews)[0]["event"] != "APPROVED" || (*reviews)[0]["body"] != "Looks good" {
+		t.Errorf("COMMENT_BODY should be the body of the review: %v", *reviews)
+	}
+}
+
+func TestReviewDecisionWithoutReviewStates(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var notes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// go-scm sends the note body as a query parameter
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/merge_requests/4/notes") {
+			notes = append(notes, r.URL.Query().Get("body"))
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider: "gitlab",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "group/project",
+		PRNumber:    4,
+		CommentBody: "Please fix the failing checks",
+		ReviewEvent: "request_changes",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := p.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(notes) != 1 || notes[0] != "Please fix the failing checks" {
+		t.Errorf("COMMENT_BODY should be posted as a comment: %v", notes)
+	}
+}
+
+func TestNewRejectsInvalidReviewEvent(t *testing.T) {
+	_, err := New(Config{SCMProvider: "github", Token: "token", Repo: "owner/repo", ReviewEvent: "lgtm"})
+	if err == nil {
+		t.Fatal("New should reject an unknown REVIEW_EVENT")
+	}
+}
+
+func TestDecideReviewEvent(t *testing.T) {
+	blocking := []ReviewComment{{Severity: "low"}, {Severity: "high"}}
+	minor := []ReviewComment{{Severity: "medium"}}
+
+	tests := []struct {
+		event   string
+		reviews []ReviewComment
+		want    string
+	}{
+		{reviewEventAuto, blocking, reviewEventRequestChanges},
+		{reviewEventAuto, minor, reviewEventApprove},
+		{reviewEventAuto, nil, reviewEventApprove},
+		{reviewEventComment, blocking, reviewEventComment},
+	}
+
+	for _, tt := range tests {
+		if got := decideReviewEvent(tt.event, tt.reviews); got != tt.want {
+			t.Errorf("decideReviewEvent(%q, %d reviews) = %q, want %q", tt.event, len(tt.reviews), got, tt.want)
+		}
+	}
+}
+
+func TestSubmitReviewDecisionHarness(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider: "harness",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "repo",
+		PRNumber:    3,
+		CommitSHA:   "abc",
+		ReviewEvent: "auto",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := p.submitReviewDecision(context.Background(), []ReviewComment{{Severity: "critical"}}); err != nil {
+		t.Fatalf("submitReviewDecision failed: %v", err)
+	}
+	if path != "/gateway/code/api/v1/repos/repo/pullreq/3/reviews" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if body["decision"] != "changereq" || body["commit_sha"] != "abc" {
+		t.Errorf("unexpected review %v", body)
+	}
+
+	if err := p.submitReviewDecision(context.Background(), nil); err == nil {
+		t.Error("auto without findings should fail")
+	}
+}
+
+func TestPostReviewsHarness(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var comments []map[string]interface{}
+	var decision string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/gateway/code/api/v1/repos/repo/pullreq/3":
+			_, _ = w.Write([]byte(`{"number": 3, "source_sha": "head", "merge_base_sha": "base"}`))
+			return
+		case "/gateway/code/api/v1/repos/repo/pullreq/3/comments":
+			comments = append(comments, body)
+		case "/gateway/code/api/v1/repos/repo/pullreq/3/reviews":
+			decision, _ = body["decision"].(string)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{
+		SCMProvider: "harness",
+		SCMEndpoint: srv.URL,
+		Token:       "token",
+		Repo:        "repo",
+		PRNumber:    3,
+		ReviewEvent: "approve",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	reviews := []ReviewComment{{FilePath: "main.go", LineNumberStart: 2, LineNumberEnd: 4, Review: "Simplify"}}
+	if err := p.postReviews(context.Background(), reviews, renderOptions{Ranges: true}); err != nil {
+		t.Fatalf("postReviews failed: %v", err)
+	}
+
+	if len(comments) != 1 {
+		t.Fatalf("got %d comments, want 1", len(comments))
+	}
+	if comments[0]["line_start"] != float64(2) || comments[0]["line_end"] != float64(4) || comments[0]["target_commit_sha"] != "base" {
+		t.Errorf("unexpected comment %v", comments[0])
+	}
+	if decision != "approved" {
+		t.Errorf("decision = %q, want approved", decision)
+	}
+}
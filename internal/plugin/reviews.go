@@ -0,0 +1,284 @@
+package plugin
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// expandCommentsFiles resolves the configured comments file entries into a
+// de-duplicated list of paths. Entries containing glob characters are expanded
+// (including ** for any number of directories); plain paths are kept as-is.
+func expandCommentsFiles(entries []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		matches := []string{entry}
+		if hasGlobMeta(entry) {
+			var err error
+			matches, err = globFiles(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid comments file pattern %q: %w", entry, err)
+			}
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// globFiles expands a glob pattern into matching regular files. Patterns
+// without ** are delegated to filepath.Glob; otherwise the tree below the
+// static prefix of the pattern is walked and matched segment by segment.
+func globFiles(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return regularFiles(matches), nil
+	}
+
+	// Validate the pattern up front so syntax errors are not swallowed by the walk
+	if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// Walk from the longest prefix that contains no glob characters
+	var rootSegments []string
+	for _, segment := range segments {
+		if hasGlobMeta(segment) {
+			break
+		}
+		rootSegments = append(rootSegments, segment)
+	}
+	root := strings.Join(rootSegments, "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel := filepath.ToSlash(p)
+		if root == "." {
+			rel = strings.TrimPrefix(rel, "./")
+		}
+		if matchSegments(segments, strings.Split(rel, "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// matchSegments matches path segments against pattern segments, where a **
+// segment matches zero or more path segments.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
+
+func regularFiles(paths []string) []string {
+	var files []string
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// loadReviews reads and merges the reviews from every comments file, tagging
+// each review with the file it came from. Missing and empty files are skipped.
+// Unreadable files and invalid entries are skipped with a warning, or fail the
+// load when strict.
+func loadReviews(files []string, strict bool, log *logrus.Entry) ([]ReviewComment, error) {
+	var reviews []ReviewComment
+
+	for _, file := range files {
+		fileReviews, err := loadReviewsFile(file, strict, log)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			log.WithError(err).WithField("file", file).Warn("skipping comments file")
+			continue
+		}
+
+		for i := range fileReviews {
+			fileReviews[i].Source = file
+		}
+		reviews = append(reviews, fileReviews...)
+
+		log.WithFields(logrus.Fields{
+			"file":  file,
+			"count": len(fileReviews),
+		}).Info("loaded reviews from file")
+	}
+
+	return reviews, nil
+}
+
+func loadReviewsFile(file string, strict bool, log *logrus.Entry) ([]ReviewComment, error) {
+	// Check if file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		log.WithField("file", file).Warn("comments file not found, skipping")
+		return nil, nil
+	}
+
+	// Read the file
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read comments file %s: %w", file, err)
+	}
+
+	// Handle empty file
+	if len(data) == 0 {
+		log.WithField("file", file).Warn("comments file is empty, skipping")
+		return nil, nil
+	}
+
+	// Secret scanner reports are converted directly, with secrets masked
+	if report := detectSecretReport(data); report != "" {
+		reviews, err := parseSecretReport(report, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse comments file %s: %w", file, err)
+		}
+		fingerprintReviews(reviews)
+		log.WithFields(logrus.Fields{
+			"file":   file,
+			"format": report,
+		}).Info("loaded secret scanner report")
+		return reviews, nil
+	}
+
+	// SARIF logs from code scanners are converted directly
+	if isSARIF(file, data) {
+		reviews, dropped, err := parseSARIF(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse comments file %s: %w", file, err)
+		}
+		if dropped > 0 {
+			log.WithFields(logrus.Fields{
+				"file":  file,
+				"count": dropped,
+			}).Warn("skipping SARIF results without a file location in the repository")
+		}
+		fingerprintReviews(reviews)
+		log.WithFields(logrus.Fields{
+			"file":   file,
+			"format": formatSARIF,
+		}).Info("loaded SARIF log")
+		return reviews, nil
+	}
+
+	// YAML and TOML are converted to JSON and validated the same way
+	format := detectFormat(file, data)
+	data, err = toJSON(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comments file %s: %w", file, err)
+	}
+
+	// Parse and validate
+	reviews, validationErrs, err := validateReviews(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comments file %s: %w", file, err)
+	}
+
+	// Version 1 comments are posted exactly as before versioning, without a
+	// fingerprint marker
+	if reviewsVersion(data) >= ReviewsVersion2 {
+		fingerprintReviews(reviews)
+	}
+
+	if len(validationErrs) > 0 {
+		if strict {
+			return nil, fmt.Errorf("invalid comments file %s: %w", file, validationErrs)
+		}
+		for _, verr := range validationErrs {
+			// Problems with the document itself are not about any one review
+			if verr.Index < 0 {
+				log.WithFields(logrus.Fields{
+					"file":    file,
+					"pointer": verr.Pointer,
+				}).Warn("invalid comments file: " + verr.Message)
+				continue
+			}
+			log.WithFields(logrus.Fields{
+				"file":    file,
+				"index":   verr.Index,
+				"pointer": verr.Pointer,
+			}).Warn("skipping invalid review: " + verr.Message)
+		}
+	}
+
+	return reviews, nil
+}
+
+// fingerprintReviews derives a fingerprint for the reviews that have none
+func fingerprintReviews(reviews []ReviewComment) {
+	for i := range reviews {
+		if reviews[i].Fingerprint == "" {
+			reviews[i].Fingerprint = reviewFingerprint(reviews[i])
+		}
+	}
+}
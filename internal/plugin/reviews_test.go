@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpandCommentsFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "reports", "a.json"), "{}")
+	writeFile(t, filepath.Join(dir, "reports", "lint", "b.json"), "{}")
+	writeFile(t, filepath.Join(dir, "reports", "lint", "deep", "c.json"), "{}")
+	writeFile(t, filepath.Join(dir, "reports", "notes.txt"), "")
+
+	files, err := expandCommentsFiles([]string{
+		filepath.Join(dir, "reports", "**", "*.json"),
+		filepath.Join(dir, "reports", "a.json"), // duplicate of a glob match
+		filepath.Join(dir, "missing.json"),
+	})
+	if err != nil {
+		t.Fatalf("expandCommentsFiles failed: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "reports", "a.json"),
+		filepath.Join(dir, "reports", "lint", "b.json"),
+		filepath.Join(dir, "reports", "lint", "deep", "c.json"),
+		filepath.Join(dir, "missing.json"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expandCommentsFiles = %v, want %v", files, want)
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"reports/**/*.sarif", "reports/x.sarif", true},
+		{"reports/**/*.sarif", "reports/a/b/x.sarif", true},
+		{"reports/**/*.sarif", "reports/a/b/x.json", false},
+		{"**/reviews.json", "reviews.json", true},
+		{"reports/*.json", "reports/a/x.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadReviewsMergesSources(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+	writeFile(t, first, `{"reviews":[{"file_path":"a.go","line_number_start":1,"line_number_end":1,"type":"bug","review":"one"}]}`)
+	writeFile(t, second, `{"version":2,"reviews":[{"file_path":"b.go","line_number_start":2,"line_number_end":3,"review":"two"}]}`)
+
+	reviews, err := loadReviews([]string{first, second, filepath.Join(dir, "missing.json")}, false, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("loadReviews failed: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("loadReviews returned %d reviews, want 2", len(reviews))
+	}
+	if reviews[0].Source != first || reviews[1].Source != second {
+		t.Errorf("unexpected sources: %q, %q", reviews[0].Source, reviews[1].Source)
+	}
+
+	// Only version 2 comments carry a fingerprint marker
+	if reviews[0].Fingerprint != "" || reviews[1].Fingerprint == "" {
+		t.Errorf("expected a fingerprint for the version 2 review only, got %q and %q", reviews[0].Fingerprint, reviews[1].Fingerprint)
+	}
+}
+
+func TestLoadReviewsSkipsBadFiles(t *testing.T) {
+	logrus.SetLevel(logrus.PanicLevel)
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.yaml")
+	scan := filepath.Join(dir, "reports", "scan.sarif")
+	writeFile(t, good, `{"reviews":[{"file_path":"a.go","line_number_start":1,"line_number_end":1,"review":"one"}]}`)
+	writeFile(t, bad, "reviews: [unclosed")
+	writeFile(t, scan, sarifReport)
+
+	files, err := expandCommentsFiles([]string{good, bad, filepath.Join(dir, "reports", "**", "*.sarif")})
+	if err != nil {
+		t.Fatalf("expandCommentsFiles failed: %v", err)
+	}
+
+	log := logrus.NewEntry(logrus.StandardLogger())
+	reviews, err := loadReviews(files, false, log)
+	if err != nil {
+		t.Fatalf("loadReviews failed: %v", err)
+	}
+	if len(reviews) != 3 || reviews[0].Source != good || reviews[1].Source != scan {
+		t.Errorf("expected the good file and the SARIF log, got %+v", reviews)
+	}
+
+	if _, err := loadReviews(files, true, log); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("strict loadReviews error = %v, want one naming %s", err, bad)
+	}
+}
@@ -0,0 +1,301 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// formatSARIF is the Static Analysis Results Interchange Format written by
+// most code scanners
+const formatSARIF = "sarif"
+
+// sarifLog holds the parts of a SARIF 2.1.0 log that become reviews
+type sarifLog struct {
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	// OriginalURIBaseIDs locates the uriBaseId of artifact locations, such
+	// as %SRCROOT% for the checkout the scanner ran on
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds"`
+	Tool               struct {
+		Driver struct {
+			Name  string      `json:"name"`
+			Rules []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRule struct {
+	ID                   string `json:"id"`
+	HelpURI              string `json:"helpUri"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties struct {
+		SecuritySeverity string   `json:"security-severity"`
+		Tags             []string `json:"tags"`
+	} `json:"properties"`
+}
+
+type sarifResult struct {
+	RuleID    string `json:"ruleId"`
+	RuleIndex *int   `json:"ruleIndex"`
+	Level     string `json:"level"`
+	Message   struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+			Region           struct {
+				StartLine   int `json:"startLine"`
+				EndLine     int `json:"endLine"`
+				StartColumn int `json:"startColumn"`
+				EndColumn   int `json:"endColumn"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+// isSARIF reports whether data is a SARIF log: a .sarif file, or a JSON
+// object with a runs array and a SARIF version or schema
+func isSARIF(file string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(file), ".sarif") {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return false
+	}
+	var doc struct {
+		Schema  string          `json:"$schema"`
+		Version string          `json:"version"`
+		Runs    json.RawMessage `json:"runs"`
+	}
+	if err := json.Unmarshal(trimmed, &doc); err != nil || doc.Runs == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(doc.Schema), "sarif") || strings.HasPrefix(doc.Version, "2.")
+}
+
+// parseSARIF converts the results of every run of a SARIF log into reviews.
+// Results without a file location in the repository cannot be anchored and
+// are dropped; dropped is their count.
+func parseSARIF(data []byte) (reviews []ReviewComment, dropped int, err error) {
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, 0, fmt.Errorf("invalid SARIF log: %w", err)
+	}
+	if log.Version != "" && !strings.HasPrefix(log.Version, "2.") {
+		return nil, 0, fmt.Errorf("unsupported SARIF version %q", log.Version)
+	}
+
+	for _, run := range log.Runs {
+		rules := make(map[string]sarifRule, len(run.Tool.Driver.Rules))
+		for _, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = rule
+		}
+
+		for _, result := range run.Results {
+			if len(result.Locations) == 0 {
+				dropped++
+				continue
+			}
+			location := result.Locations[0].PhysicalLocation
+			file := sarifPath(location.ArtifactLocation, run.OriginalURIBaseIDs)
+			if file == "" {
+				dropped++
+				continue
+			}
+
+			rule, ok := rules[result.RuleID]
+			if !ok && result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(run.Tool.Driver.Rules) {
+				rule = run.Tool.Driver.Rules[*result.RuleIndex]
+			}
+			ruleID := result.RuleID
+			if ruleID == "" {
+				ruleID = rule.ID
+			}
+
+			region := location.Region
+			start := max(region.StartLine, 1)
+			end := max(region.EndLine, start)
+
+			review := ReviewComment{
+				FilePath:         file,
+				LineNumberStart:  start,
+				LineNumberEnd:    end,
+				Type:             run.Tool.Driver.Name,
+				Review:           result.Message.Text,
+				Severity:         sarifSeverity(result.Level, rule),
+				RuleID:           ruleID,
+				DocumentationURL: rule.HelpURI,
+				Tags:             rule.Properties.Tags,
+				Fingerprint:      sarifFingerprint(result.PartialFingerprints),
+			}
+			if review.Review == "" {
+				review.Review = "Finding reported by " + run.Tool.Driver.Name + "."
+			}
+
+			// SARIF end columns are exclusive
+			if region.StartColumn > 0 && region.EndColumn > region.StartColumn {
+				review.StartColumn = region.StartColumn
+				review.EndColumn = region.EndColumn - 1
+			}
+
+			reviews = append(reviews, review)
+		}
+	}
+
+	return reviews, dropped, nil
+}
+
+// sarifSourceRoot is the uriBaseId scanners give the repository checkout
+const sarifSourceRoot = "SRCROOT"
+
+// sarifPath turns an artifact location into a path relative to the
+// repository. A relative URI is resolved against its uriBaseId, and an
+// absolute path is made relative to %SRCROOT% or the working directory. It
+// returns "" when the file is outside the repository, or relative to a base
+// id the run does not locate other than %SRCROOT%.
+func sarifPath(location sarifArtifactLocation, bases map[string]sarifArtifactLocation) string {
+	if id := sarifBaseID(location.URIBaseID); id != "" && id != sarifSourceRoot {
+		if _, ok := bases[id]; !ok {
+			return ""
+		}
+	}
+
+	file, ok := sarifFilePath(sarifResolve(location, bases, 0))
+	if !ok || file == "" {
+		return ""
+	}
+	if !strings.HasPrefix(file, "/") {
+		file = strings.TrimPrefix(file, "./")
+		if file == ".." || strings.HasPrefix(file, "../") {
+			return ""
+		}
+		return file
+	}
+
+	if root, ok := bases[sarifSourceRoot]; ok {
+		if dir, ok := sarifFilePath(sarifResolve(root, bases, 0)); ok && strings.HasPrefix(dir, "/") {
+			if rel, err := filepath.Rel(dir, file); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	if rel := relativePath(file); !strings.HasPrefix(rel, "/") {
+		return rel
+	}
+	return ""
+}
+
+// sarifResolve returns the URI of an artifact location, resolved against its
+// uriBaseId when the run defines it. Base ids may refer to other base ids.
+func sarifResolve(location sarifArtifactLocation, bases map[string]sarifArtifactLocation, depth int) string {
+	base, ok := bases[sarifBaseID(location.URIBaseID)]
+	if !ok || depth > len(bases) {
+		return location.URI
+	}
+	ref, err := url.Parse(location.URI)
+	if err != nil || ref.IsAbs() || strings.HasPrefix(ref.Path, "/") {
+		return location.URI
+	}
+
+	baseURL, err := url.Parse(sarifResolve(base, bases, depth+1))
+	if err != nil {
+		return location.URI
+	}
+	// Base URIs name directories, whether or not they end in a slash
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+// sarifBaseID normalizes a uriBaseId, which some scanners write as %SRCROOT%
+func sarifBaseID(id string) string {
+	return strings.Trim(id, "%")
+}
+
+// sarifFilePath returns the path of a file URI or relative reference; other
+// schemes are not files in the repository
+func sarifFilePath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "file" && u.Scheme != "") {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// sarifSeverity maps the result level, or the rule's security severity
+// score when present, to a review severity. Results without a level take the
+// rule's default level, which is warning unless configured.
+func sarifSeverity(level string, rule sarifRule) string {
+	var score float64
+	if _, err := fmt.Sscanf(rule.Properties.SecuritySeverity, "%g", &score); err == nil {
+		switch {
+		case score >= 9:
+			return SeverityCritical
+		case score >= 7:
+			return SeverityHigh
+		case score >= 4:
+			return SeverityMedium
+		case score > 0:
+			return SeverityLow
+		}
+	}
+
+	if level == "" {
+		level = rule.DefaultConfiguration.Level
+	}
+	switch level {
+	case "error":
+		return SeverityHigh
+	case "note":
+		return SeverityLow
+	case "none":
+		return SeverityInfo
+	default:
+		return SeverityMedium
+	}
+}
+
+// sarifFingerprint derives a fingerprint from the result's partial
+// fingerprints, so findings keep the identity the scanner gave them. Results
+// without any get one from reviewFingerprint.
+func sarifFingerprint(fingerprints map[string]string) string {
+	if len(fingerprints) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fingerprints))
+	for key := range fingerprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, key+"="+fingerprints[key])
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sarifReport = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "originalUriBaseIds": {"SRCROOT": {"uri": "file:///home/runner/work/repo/"}},
+    "tool": {"driver": {"name": "CodeQL", "rules": [
+      {"id": "go/sql-injection", "helpUri": "https://codeql.github.com/go-sql-injection",
+       "properties": {"security-severity": "8.8", "tags": ["security"]}},
+      {"id": "go/unused", "defaultConfiguration": {"level": "note"}}
+    ]}},
+    "results": [
+      {"ruleId": "go/sql-injection", "level": "error", "message": {"text": "Query built from user input."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "db/query.go", "uriBaseId": "SRCROOT"},
+         "region": {"startLine": 10, "endLine": 12, "startColumn": 5, "endColumn": 9}}}],
+       "partialFingerprints": {"primaryLocationLineHash": "abc:1"}},
+      {"ruleIndex": 1, "message": {"text": "Unused variable."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 3}}}]},
+      {"ruleId": "go/unused", "message": {"text": "No location."}},
+      {"ruleId": "go/unused", "message": {"text": "Outside the repository."},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file:///usr/lib/go/src/fmt/print.go"}, "region": {"startLine": 1}}}]}
+    ]
+  }]
+}`
+
+func TestIsSARIF(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		want bool
+	}{
+		{"report.json", sarifReport, true},
+		{"scan.sarif", `{}`, true},
+		{"reviews.json", `{"reviews": []}`, false},
+		{"reviews.json", `{"version": 2, "reviews": []}`, false},
+		{"gitleaks.json", `[]`, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSARIF(tt.file, []byte(tt.data)); got != tt.want {
+			t.Errorf("isSARIF(%q, %q) = %v, want %v", tt.file, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParseSARIF(t *testing.T) {
+	reviews, dropped, err := parseSARIF([]byte(sarifReport))
+	if err != nil {
+		t.Fatalf("parseSARIF failed: %v", err)
+	}
+	if len(reviews) != 2 || dropped != 2 {
+		t.Fatalf("got %d reviews and %d dropped, want 2 and 2: %+v", len(reviews), dropped, reviews)
+	}
+
+	first := reviews[0]
+	if first.FilePath != "db/query.go" || first.LineNumberStart != 10 || first.LineNumberEnd != 12 {
+		t.Errorf("unexpected location: %+v", first)
+	}
+	if first.StartColumn != 5 || first.EndColumn != 8 {
+		t.Errorf("columns = %d-%d, want 5-8", first.StartColumn, first.EndColumn)
+	}
+	if first.Severity != SeverityHigh || first.RuleID != "go/sql-injection" || first.Type != "CodeQL" {
+		t.Errorf("unexpected metadata: %+v", first)
+	}
+	if first.DocumentationURL == "" || first.Fingerprint == "" {
+		t.Errorf("expected documentation URL and fingerprint: %+v", first)
+	}
+
+	second := reviews[1]
+	if second.FilePath != "main.go" || second.LineNumberEnd != 3 || second.RuleID != "go/unused" || second.Severity != SeverityLow {
+		t.Errorf("unexpected second review: %+v", second)
+	}
+
+	if _, _, err := parseSARIF([]byte(`{"version": "1.0.0", "runs": []}`)); err == nil {
+		t.Error("parseSARIF should reject SARIF 1.x")
+	}
+}
+
+func TestSARIFPath(t *testing.T) {
+	wd, _ := os.Getwd()
+	bases := map[string]sarifArtifactLocation{
+		"SRCROOT": {URI: "file:///work/repo"},
+		"SRC":     {URI: "src/", URIBaseID: "SRCROOT"},
+		"HOME":    {URI: "file:///home/user/"},
+	}
+
+	tests := []struct {
+		location sarifArtifactLocation
+		want     string
+	}{
+		{sarifArtifactLocation{URI: "main.go"}, "main.go"},
+		{sarifArtifactLocation{URI: "./cmd/main.go"}, "cmd/main.go"},
+		{sarifArtifactLocation{URI: "db/query.go", URIBaseID: "SRCROOT"}, "db/query.go"},
+		{sarifArtifactLocation{URI: "db/query%20v2.go", URIBaseID: "SRC"}, "src/db/query v2.go"},
+		{sarifArtifactLocation{URI: "file:///work/repo/pkg/a.go"}, "pkg/a.go"},
+		{sarifArtifactLocation{URI: "file://" + filepath.ToSlash(wd) + "/b.go"}, "b.go"},
+		{sarifArtifactLocation{URI: ".cache/c.go", URIBaseID: "HOME"}, ""},
+		{sarifArtifactLocation{URI: "file:///usr/lib/go/src/fmt/print.go"}, ""},
+		{sarifArtifactLocation{URI: "pkg/a.go", URIBaseID: "%SRCROOT%"}, "pkg/a.go"},
+		{sarifArtifactLocation{URI: "lib/d.go", URIBaseID: "GOROOT"}, ""},
+		{sarifArtifactLocation{URI: "../outside.go"}, ""},
+		{sarifArtifactLocation{URI: "https://example.com/a.go"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := sarifPath(tt.location, bases); got != tt.want {
+			t.Errorf("sarifPath(%+v) = %q, want %q", tt.location, got, tt.want)
+		}
+	}
+
+	// %SRCROOT% is the repository even when the run does not locate it
+	if got := sarifPath(sarifArtifactLocation{URI: "a.go", URIBaseID: "SRCROOT"}, nil); got != "a.go" {
+		t.Errorf("sarifPath without base ids = %q, want a.go", got)
+	}
+}
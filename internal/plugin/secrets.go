@@ -0,0 +1,206 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Secret scanner report formats
+const (
+	formatGitleaks   = "gitleaks"
+	formatTrufflehog = "trufflehog"
+)
+
+const redacted = "[REDACTED]"
+
+// gitleaksFinding holds the fields of a gitleaks JSON report entry that are
+// safe to use. Secret is kept only long enough to mask it out of Match.
+type gitleaksFinding struct {
+	RuleID      string   `json:"RuleID"`
+	Description string   `json:"Description"`
+	File        string   `json:"File"`
+	StartLine   int      `json:"StartLine"`
+	EndLine     int      `json:"EndLine"`
+	StartColumn int      `json:"StartColumn"`
+	EndColumn   int      `json:"EndColumn"`
+	Match       string   `json:"Match"`
+	Secret      string   `json:"Secret"`
+	Tags        []string `json:"Tags"`
+}
+
+// trufflehogFinding holds the fields of a trufflehog JSON line that are safe
+// to use. The Raw/RawV2/Redacted values are deliberately not decoded.
+type trufflehogFinding struct {
+	DetectorName   string `json:"DetectorName"`
+	Verified       bool   `json:"Verified"`
+	SourceMetadata struct {
+		Data map[string]struct {
+			File string `json:"file"`
+			Line int    `json:"line"`
+		} `json:"Data"`
+	} `json:"SourceMetadata"`
+}
+
+// detectSecretReport reports whether data is a gitleaks or trufflehog JSON
+// report, returning "" for anything else.
+func detectSecretReport(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+
+	// gitleaks writes a JSON array of findings
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var entries []map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &entries); err == nil {
+			if len(entries) == 0 {
+				return formatGitleaks
+			}
+			if _, ok := entries[0]["RuleID"]; ok {
+				return formatGitleaks
+			}
+		}
+		return ""
+	}
+
+	// trufflehog writes one JSON object per line
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		line := trimmed
+		if i := bytes.IndexByte(trimmed, '\n'); i >= 0 {
+			line = trimmed[:i]
+		}
+		var entry map[string]json.RawMessage
+		if err := json.Unmarshal(line, &entry); err == nil {
+			_, hasDetector := entry["DetectorName"]
+			_, hasSource := entry["SourceMetadata"]
+			if hasDetector && hasSource {
+				return formatTrufflehog
+			}
+		}
+	}
+
+	return ""
+}
+
+// parseSecretReport converts a secret scanner report into reviews. Secret
+// values are masked here, before the text can reach logs or the SCM.
+func parseSecretReport(format string, data []byte) ([]ReviewComment, error) {
+	switch format {
+	case formatGitleaks:
+		return parseGitleaks(data)
+	case formatTrufflehog:
+		return parseTrufflehog(data)
+	default:
+		return nil, fmt.Errorf("unsupported secret report format: %s", format)
+	}
+}
+
+func parseGitleaks(data []byte) ([]ReviewComment, error) {
+	var findings []gitleaksFinding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, fmt.Errorf("invalid gitleaks report: %w", err)
+	}
+
+	reviews := make([]ReviewComment, 0, len(findings))
+	for _, f := range findings {
+		start := max(f.StartLine, 1)
+		end := max(f.EndLine, start)
+
+		text := fmt.Sprintf("Possible secret detected: %s.", redact(f.Description, f.Secret))
+		// Without the secret value the match cannot be masked reliably, so drop it
+		if f.Secret != "" && f.Match != "" {
+			text += fmt.Sprintf("\n\nMatch: `%s`", redact(f.Match, f.Secret))
+		}
+		text += "\n\nRotate the credential and remove it from the history."
+
+		reviews = append(reviews, ReviewComment{
+			FilePath:        relativePath(f.File),
+			LineNumberStart: start,
+			LineNumberEnd:   end,
+			Type:            "secret",
+			Review:          text,
+			Severity:        SeverityHigh,
+			RuleID:          f.RuleID,
+			Tags:            f.Tags,
+		})
+	}
+
+	return reviews, nil
+}
+
+func parseTrufflehog(data []byte) ([]ReviewComment, error) {
+	var reviews []ReviewComment
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var f trufflehogFinding
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, fmt.Errorf("invalid trufflehog report line %d: %w", n, err)
+		}
+
+		// Source metadata is keyed by source type (Filesystem, Git, Github, ...)
+		var file string
+		var lineNumber int
+		for _, source := range f.SourceMetadata.Data {
+			if source.File != "" {
+				file, lineNumber = source.File, source.Line
+				break
+			}
+		}
+		if file == "" {
+			continue
+		}
+		lineNumber = max(lineNumber, 1)
+
+		severity := SeverityHigh
+		status := "unverified"
+		if f.Verified {
+			severity = SeverityCritical
+			status = "verified live"
+		}
+
+		reviews = append(reviews, ReviewComment{
+			FilePath:        relativePath(file),
+			LineNumberStart: lineNumber,
+			LineNumberEnd:   lineNumber,
+			Type:            "secret",
+			Review:          fmt.Sprintf("Possible %s secret detected (%s).\n\nRotate the credential and remove it from the history.", f.DetectorName, status),
+			Severity:        severity,
+			RuleID:          f.DetectorName,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid trufflehog report: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// redact replaces every occurrence of the secret in text
+func redact(text, secret string) string {
+	if secret == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, secret, redacted)
+}
+
+// relativePath makes absolute scanner paths relative to the working directory,
+// which is the repository root in CI.
+func relativePath(file string) string {
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(file), "./")
+}
@@ -0,0 +1,55 @@
+package plugin
+
+import "strings"
+
+// Review severities, from most to least severe
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+var severityRanks = map[string]int{
+	SeverityCritical: 5,
+	SeverityHigh:     4,
+	SeverityMedium:   3,
+	SeverityLow:      2,
+	SeverityInfo:     1,
+}
+
+func validSeverity(severity string) bool {
+	_, ok := severityRanks[strings.ToLower(severity)]
+	return ok
+}
+
+// severityOf returns the normalized severity of a review. Reviews without a
+// severity (including all version 1 reviews) are treated as medium.
+func severityOf(review ReviewComment) string {
+	severity := strings.ToLower(review.Severity)
+	if _, ok := severityRanks[severity]; ok {
+		return severity
+	}
+	return SeverityMedium
+}
+
+// severityRank orders severities; unknown values rank below info.
+func severityRank(severity string) int {
+	return severityRanks[strings.ToLower(severity)]
+}
+
+func severityEmoji(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "🔴"
+	case SeverityHigh:
+		return "🟠"
+	case SeverityMedium:
+		return "🟡"
+	case SeverityLow:
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
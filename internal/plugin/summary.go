@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+var severityOrder = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// countBySeverity counts reviews per normalized severity
+func countBySeverity(reviews []ReviewComment) map[string]int {
+	counts := make(map[string]int)
+	for _, review := range reviews {
+		counts[severityOf(review)]++
+	}
+	return counts
+}
+
+// highestSeverity returns the most severe severity among the reviews, or ""
+// when there are none.
+func highestSeverity(reviews []ReviewComment) string {
+	highest := ""
+	for _, review := range reviews {
+		if severity := severityOf(review); severityRank(severity) > severityRank(highest) {
+			highest = severity
+		}
+	}
+	return highest
+}
+
+// summaryTitle is a one-line description of the findings
+func summaryTitle(reviews []ReviewComment) string {
+	if len(reviews) == 0 {
+		return "No findings"
+	}
+	if len(reviews) == 1 {
+		return "1 finding"
+	}
+	return fmt.Sprintf("%d findings", len(reviews))
+}
+
+// summaryMarkdown renders a markdown report of the findings: a count per
+// severity followed by a table of every finding.
+func summaryMarkdown(reviews []ReviewComment) string {
+	if len(reviews) == 0 {
+		return "✅ No findings."
+	}
+
+	var b strings.Builder
+
+	counts := countBySeverity(reviews)
+	var totals []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			totals = append(totals, fmt.Sprintf("%s %d %s", severityEmoji(severity), counts[severity], severity))
+		}
+	}
+	fmt.Fprintf(&b, "**%s:** %s\n\n", summaryTitle(reviews), strings.Join(totals, " · "))
+
+	b.WriteString("| Severity | Location | Type | Finding |\n")
+	b.WriteString("|----------|----------|------|---------|\n")
+	for _, review := range reviews {
+		location := fmt.Sprintf("`%s:%d`", review.FilePath, review.LineNumberStart)
+		if review.LineNumberEnd > review.LineNumberStart {
+			location = fmt.Sprintf("`%s:%d-%d`", review.FilePath, review.LineNumberStart, review.LineNumberEnd)
+		}
+		fmt.Fprintf(&b, "| %s %s | %s | %s | %s |\n",
+			severityEmoji(severityOf(review)),
+			severityOf(review),
+			location,
+			tableCell(review.Type),
+			tableCell(firstLine(review.Review)),
+		)
+	}
+
+	return b.String()
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// tableCell escapes text for a single markdown table cell
+func tableCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
@@ -0,0 +1,306 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a single problem found in a reviews file.
+// Index is the position of the offending entry in the reviews array, or -1
+// for problems with the file itself.
+type ValidationError struct {
+	Index   int
+	Pointer string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Index < 0 && e.Pointer == "" {
+		return e.Message
+	}
+	if e.Index < 0 {
+		return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+	}
+	return fmt.Sprintf("review %d (%s): %s", e.Index, e.Pointer, e.Message)
+}
+
+// ValidationErrors is the report of every problem found in a reviews file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("%d validation error(s)", len(e)))
+	for _, err := range e {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInteger
+	kindNumber
+	kindStringArray
+)
+
+// reviewFieldsV1 mirrors the reviewV1 definition in schema/reviews.schema.json
+var reviewFieldsV1 = map[string]fieldKind{
+	"file_path":         kindString,
+	"line_number_start": kindInteger,
+	"line_number_end":   kindInteger,
+	"type":              kindString,
+	"review":            kindString,
+}
+
+// reviewFieldsV2 mirrors the reviewV2 definition in schema/reviews.schema.json
+var reviewFieldsV2 = map[string]fieldKind{
+	"file_path":         kindString,
+	"line_number_start": kindInteger,
+	"line_number_end":   kindInteger,
+	"type":              kindString,
+	"review":            kindString,
+	"severity":          kindString,
+	"start_column":      kindInteger,
+	"end_column":        kindInteger,
+	"rule_id":           kindString,
+	"documentation_url": kindString,
+	"suggestion":        kindString,
+	"confidence":        kindNumber,
+	"tags":              kindStringArray,
+	"fingerprint":       kindString,
+}
+
+// validateReviews parses a reviews document and checks every entry against
+// the reviews schema. It returns the valid entries along with a report of
+// every invalid one; an error is returned only if the document itself cannot
+// be parsed.
+func validateReviews(data []byte) ([]ReviewComment, ValidationErrors, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	if _, ok := root.(map[string]interface{}); !ok {
+		return nil, ValidationErrors{{Index: -1, Message: "document must be an object with a reviews array"}}, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	var errs ValidationErrors
+	for _, key := range sortedKeys(doc) {
+		if key != "reviews" && key != "version" {
+			errs = append(errs, ValidationError{Index: -1, Pointer: pointer(key), Message: "unknown field"})
+		}
+	}
+
+	// Files without a version predate versioning and are version 1
+	version := ReviewsVersion1
+	if raw, ok := doc["version"]; ok {
+		if !hasKind(raw, kindInteger) {
+			errs = append(errs, ValidationError{Index: -1, Pointer: "/version", Message: "must be an integer"})
+			return nil, errs, nil
+		}
+		_ = json.Unmarshal(raw, &version)
+		if version < ReviewsVersion1 || version > LatestReviewsVersion {
+			errs = append(errs, ValidationError{Index: -1, Pointer: "/version", Message: fmt.Sprintf("unsupported version %d (latest is %d)", version, LatestReviewsVersion)})
+			return nil, errs, nil
+		}
+	}
+
+	fields := reviewFieldsV1
+	if version >= ReviewsVersion2 {
+		fields = reviewFieldsV2
+	}
+
+	raw, ok := doc["reviews"]
+	if !ok {
+		errs = append(errs, ValidationError{Index: -1, Pointer: "/reviews", Message: "required field is missing"})
+		return nil, errs, nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		errs = append(errs, ValidationError{Index: -1, Pointer: "/reviews", Message: "must be an array"})
+		return nil, errs, nil
+	}
+
+	var reviews []ReviewComment
+	for i, entry := range entries {
+		review, entryErrs := validateReview(i, entry, fields)
+		if len(entryErrs) > 0 {
+			errs = append(errs, entryErrs...)
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, errs, nil
+}
+
+// reviewsVersion returns the schema version of a valid reviews document;
+// documents without a version are version 1
+func reviewsVersion(data []byte) int {
+	var doc struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Version == 0 {
+		return ReviewsVersion1
+	}
+	return doc.Version
+}
+
+func validateReview(index int, entry json.RawMessage, reviewFields map[string]fieldKind) (ReviewComment, ValidationErrors) {
+	var review ReviewComment
+	var errs ValidationErrors
+
+	fail := func(ptr, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Index: index, Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	base := fmt.Sprintf("/reviews/%d", index)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(entry, &fields); err != nil || fields == nil {
+		fail(base, "must be an object")
+		return review, errs
+	}
+
+	// Check field names and types before decoding into the struct
+	for _, key := range sortedKeys(fields) {
+		kind, known := reviewFields[key]
+		if !known {
+			fail(base+pointer(key), "unknown field")
+			continue
+		}
+		if !hasKind(fields[key], kind) {
+			fail(base+pointer(key), "must be %s", kindName(kind))
+		}
+	}
+	if len(errs) > 0 {
+		return review, errs
+	}
+
+	if err := json.Unmarshal(entry, &review); err != nil {
+		fail(base, "%v", err)
+		return review, errs
+	}
+
+	if _, ok := fields["file_path"]; !ok {
+		fail(base+"/file_path", "required field is missing")
+	} else if strings.TrimSpace(review.FilePath) == "" {
+		fail(base+"/file_path", "must not be empty")
+	}
+
+	if _, ok := fields["line_number_start"]; !ok {
+		fail(base+"/line_number_start", "required field is missing")
+	} else if review.LineNumberStart < 1 {
+		fail(base+"/line_number_start", "must be >= 1, got %d", review.LineNumberStart)
+	}
+
+	if _, ok := fields["line_number_end"]; !ok {
+		fail(base+"/line_number_end", "required field is missing")
+	} else if review.LineNumberEnd < 1 {
+		fail(base+"/line_number_end", "must be >= 1, got %d", review.LineNumberEnd)
+	} else if review.LineNumberStart > review.LineNumberEnd {
+		fail(base+"/line_number_end", "must be >= line_number_start (%d), got %d", review.LineNumberStart, review.LineNumberEnd)
+	}
+
+	if _, ok := fields["review"]; !ok {
+		fail(base+"/review", "required field is missing")
+	} else if strings.TrimSpace(review.Review) == "" {
+		fail(base+"/review", "must not be empty")
+	}
+
+	if review.Severity != "" && !validSeverity(review.Severity) {
+		fail(base+"/severity", "must be one of critical, high, medium, low, info, got %q", review.Severity)
+	}
+
+	if _, ok := fields["start_column"]; ok && review.StartColumn < 1 {
+		fail(base+"/start_column", "must be >= 1, got %d", review.StartColumn)
+	}
+	if _, ok := fields["end_column"]; ok {
+		if review.EndColumn < 1 {
+			fail(base+"/end_column", "must be >= 1, got %d", review.EndColumn)
+		} else if review.StartColumn > review.EndColumn && review.LineNumberStart == review.LineNumberEnd {
+			fail(base+"/end_column", "must be >= start_column (%d) on a single line, got %d", review.StartColumn, review.EndColumn)
+		}
+	}
+
+	if review.DocumentationURL != "" {
+		if u, err := url.Parse(review.DocumentationURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail(base+"/documentation_url", "must be an absolute http(s) URL")
+		}
+	}
+
+	if review.Confidence < 0 || review.Confidence > 1 {
+		fail(base+"/confidence", "must be between 0 and 1, got %g", review.Confidence)
+	}
+
+	for i, tag := range review.Tags {
+		if strings.TrimSpace(tag) == "" {
+			fail(fmt.Sprintf("%s/tags/%d", base, i), "must not be empty")
+		}
+	}
+
+	return review, errs
+}
+
+func hasKind(raw json.RawMessage, kind fieldKind) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return false
+	}
+	switch kind {
+	case kindString:
+		var s string
+		return json.Unmarshal(raw, &s) == nil && raw[0] == '"'
+	case kindInteger:
+		var n int
+		return json.Unmarshal(raw, &n) == nil
+	case kindNumber:
+		var f float64
+		return json.Unmarshal(raw, &f) == nil
+	case kindStringArray:
+		var a []string
+		return json.Unmarshal(raw, &a) == nil && raw[0] == '['
+	}
+	return false
+}
+
+func kindName(kind fieldKind) string {
+	switch kind {
+	case kindString:
+		return "a string"
+	case kindInteger:
+		return "an integer"
+	case kindNumber:
+		return "a number"
+	case kindStringArray:
+		return "an array of strings"
+	}
+	return "valid"
+}
+
+// pointer returns the JSON pointer segment for a key (RFC 6901)
+func pointer(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+	return "/" + key
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestValidateReviews(t *testing.T) {
+	data := []byte(`{
+		"reviews": [
+			{"file_path": "a.go", "line_number_start": 1, "line_number_end": 2, "type": "bug", "review": "ok"},
+			{"line_number_start": 1, "line_number_end": 1, "review": "no path"},
+			{"file_path": "b.go", "line_number_start": 0, "line_number_end": 1, "review": "zero start"},
+			{"file_path": "c.go", "line_number_start": 5, "line_number_end": 3, "review": "reversed"},
+			{"file_path": "d.go", "line_number_start": 1, "line_number_end": 1, "review": "extra", "severity": "high"},
+			{"file_path": "e.go", "line_number_start": "1", "line_number_end": 1, "review": "wrong type"}
+		]
+	}`)
+
+	reviews, errs, err := validateReviews(data)
+	if err != nil {
+		t.Fatalf("validateReviews failed: %v", err)
+	}
+
+	if len(reviews) != 1 || reviews[0].FilePath != "a.go" {
+		t.Errorf("expected only the first review to be valid, got %+v", reviews)
+	}
+
+	want := []ValidationError{
+		{Index: 1, Pointer: "/reviews/1/file_path"},
+		{Index: 2, Pointer: "/reviews/2/line_number_start"},
+		{Index: 3, Pointer: "/reviews/3/line_number_end"},
+		{Index: 4, Pointer: "/reviews/4/severity"},
+		{Index: 5, Pointer: "/reviews/5/line_number_start"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d validation errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Index != w.Index || errs[i].Pointer != w.Pointer {
+			t.Errorf("error %d = %+v, want index %d pointer %s", i, errs[i], w.Index, w.Pointer)
+		}
+	}
+}
+
+func TestValidateReviewsDocument(t *testing.T) {
+	_, errs, err := validateReviews([]byte(`{"comments": []}`))
+	if err != nil {
+		t.Fatalf("validateReviews failed: %v", err)
+	}
+	if len(errs) != 2 || errs[0].Pointer != "/comments" || errs[1].Pointer != "/reviews" {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+
+	if _, _, err := validateReviews([]byte(`not json`)); err == nil {
+		t.Error("validateReviews should fail on malformed JSON")
+	}
+}
+
+func TestValidateReviewsVersion2(t *testing.T) {
+	data := []byte(`{
+		"version": 2,
+		"reviews": [
+			{"file_path": "a.go", "line_number_start": 1, "line_number_end": 1, "review": "ok",
+			 "severity": "high", "start_column": 3, "end_column": 8, "rule_id": "R1",
+			 "documentation_url": "https://example.com/r1", "suggestion": "x", "confidence": 0.9,
+			 "tags": ["a"], "fingerprint": "f"},
+			{"file_path": "b.go", "line_number_start": 1, "line_number_end": 1, "review": "bad", "severity": "urgent"},
+			{"file_path": "c.go", "line_number_start": 1, "line_number_end": 1, "review": "bad", "confidence": 2}
+		]
+	}`)
+
+	reviews, errs, err := validateReviews(data)
+	if err != nil {
+		t.Fatalf("validateReviews failed: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].Severity != "high" || reviews[0].EndColumn != 8 {
+		t.Errorf("unexpected valid reviews: %+v", reviews)
+	}
+	if len(errs) != 2 || errs[0].Pointer != "/reviews/1/severity" || errs[1].Pointer != "/reviews/2/confidence" {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestValidateReviewsVersion1RejectsNewFields(t *testing.T) {
+	_, errs, err := validateReviews([]byte(`{"reviews": [{"file_path": "a.go", "line_number_start": 1, "line_number_end": 1, "review": "x", "severity": "high"}]}`))
+	if err != nil {
+		t.Fatalf("validateReviews failed: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Pointer != "/reviews/0/severity" {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+
+	_, errs, _ = validateReviews([]byte(`{"version": 3, "reviews": []}`))
+	if len(errs) != 1 || errs[0].Pointer != "/version" {
+		t.Errorf("unexpected validation errors for unsupported version: %v", errs)
+	}
+}
+
+func TestValidateReviewsRootType(t *testing.T) {
+	for _, data := range []string{`[]`, `"reviews"`, `null`} {
+		_, errs, err := validateReviews([]byte(data))
+		if err != nil {
+			t.Fatalf("validateReviews(%s) failed: %v", data, err)
+		}
+		if len(errs) != 1 || errs[0].Index != -1 || errs[0].Pointer != "" {
+			t.Errorf("validateReviews(%s) errors = %v, want one document error", data, errs)
+		}
+	}
+}
+
+func TestLoadReviewsFileReportsDocumentErrors(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "reviews.json")
+	writeFile(t, file, `{"version": 3, "reviews": [{"file_path": "a.go"}]}`)
+
+	if _, err := loadReviewsFile(file, false, logrus.NewEntry(logger)); err != nil {
+		t.Fatalf("loadReviewsFile failed: %v", err)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	if _, ok := entries[0].Data["index"]; ok || !strings.HasPrefix(entries[0].Message, "invalid comments file") {
+		t.Errorf("document error logged as %q with fields %v", entries[0].Message, entries[0].Data)
+	}
+}
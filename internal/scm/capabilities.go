@@ -0,0 +1,63 @@
+package scm
+
+import "sort"
+
+// Capability is a feature that only some providers support
+type Capability string
+
+const (
+	CapabilityRanges         Capability = "ranges"          // Inline comments anchored to a line range
+	CapabilitySuggestions    Capability = "suggestions"     // Suggested changes that can be applied
+	CapabilityChecks         Capability = "checks"          // Check runs or reports with annotations
+	CapabilityReviewState    Capability = "review_state"    // Reviews that approve or request changes
+	CapabilityBatchReview    Capability = "batch_review"    // Many inline comments submitted as one review
+	CapabilityLabels         Capability = "labels"          // Pull request labels can be added and removed
+	CapabilityDescription    Capability = "description"     // Pull request descriptions can be edited
+	CapabilityReviewers      Capability = "reviewers"       // Reviewers can be requested on pull requests
+	CapabilityCommitComments Capability = "commit_comments" // Comments on commits, for builds without a pull request
+	CapabilityResolve        Capability = "resolve"         // Threads of findings no longer reported can be resolved
+)
+
+// Capabilities is the set of features a provider supports
+type Capabilities map[Capability]bool
+
+// Has reports whether the capability is in the set
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+// List returns the capabilities in the set in a stable order
+func (c Capabilities) List() []string {
+	list := make([]string, 0, len(c))
+	for capability, ok := range c {
+		if ok {
+			list = append(list, string(capability))
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// capabilities of each provider, as implemented by this plugin's clients
+var capabilities = map[Provider]Capabilities{
+	ProviderGitHub:           {CapabilityRanges: true, CapabilitySuggestions: true, CapabilityChecks: true, CapabilityReviewState: true, CapabilityBatchReview: true, CapabilityLabels: true, CapabilityDescription: true, CapabilityReviewers: true, CapabilityCommitComments: true},
+	ProviderGitHubEnterprise: {CapabilityRanges: true, CapabilitySuggestions: true, CapabilityChecks: true, CapabilityReviewState: true, CapabilityBatchReview: true, CapabilityLabels: true, CapabilityDescription: true, CapabilityReviewers: true, CapabilityCommitComments: true},
+	ProviderGitLab:           {CapabilitySuggestions: true, CapabilityBatchReview: true, CapabilityLabels: true, CapabilityDescription: true, CapabilityReviewers: true, CapabilityCommitComments: true},
+	ProviderBitbucket:        {CapabilityRanges: true, CapabilityChecks: true, CapabilityDescription: true, CapabilityCommitComments: true},
+	ProviderBitbucketServer:  {CapabilityChecks: true, CapabilityDescription: true},
+	ProviderGitea:            {CapabilityReviewState: true, CapabilityBatchReview: true, CapabilityLabels: true, CapabilityDescription: true, CapabilityReviewers: true},
+	ProviderGogs:             {},
+	ProviderHarness:          {CapabilityRanges: true, CapabilitySuggestions: true, CapabilityChecks: true, CapabilityReviewState: true, CapabilityLabels: true, CapabilityDescription: true, CapabilityReviewers: true},
+	ProviderAzureDevOps:      {CapabilityRanges: true, CapabilityDescription: true, CapabilityResolve: true},
+	ProviderGerrit:           {CapabilityRanges: true, CapabilitySuggestions: true, CapabilityBatchReview: true},
+}
+
+// Capabilities returns the features the provider supports. Unknown providers
+// support none.
+func (p Provider) Capabilities() Capabilities {
+	c := Capabilities{}
+	for capability, ok := range capabilities[p] {
+		c[capability] = ok
+	}
+	return c
+}
@@ -0,0 +1,40 @@
+package scm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	for _, provider := range SupportedProviders() {
+		if _, ok := capabilities[provider]; !ok {
+			t.Errorf("no capabilities declared for %s", provider)
+		}
+	}
+
+	github := ProviderGitHub.Capabilities()
+	if !github.Has(CapabilityChecks) || !github.Has(CapabilityRanges) {
+		t.Errorf("GitHub capabilities = %v", github.List())
+	}
+	if ProviderGogs.Capabilities().Has(CapabilitySuggestions) {
+		t.Error("Gogs should not support suggestions")
+	}
+	if got := Provider("unknown").Capabilities().List(); len(got) != 0 {
+		t.Errorf("unknown provider capabilities = %v, want none", got)
+	}
+
+	want := []string{"batch_review", "ranges", "suggestions"}
+	if got := ProviderGerrit.Capabilities().List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Gerrit capabilities = %v, want %v", got, want)
+	}
+
+	if !ProviderAzureDevOps.Capabilities().Has(CapabilityResolve) || ProviderGitHub.Capabilities().Has(CapabilityResolve) {
+		t.Error("only Azure DevOps should support resolving threads")
+	}
+
+	// Callers get their own copy
+	ProviderGitea.Capabilities()[CapabilityChecks] = true
+	if ProviderGitea.Capabilities().Has(CapabilityChecks) {
+		t.Error("modifying a returned set changed the provider's capabilities")
+	}
+}
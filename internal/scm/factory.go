@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/abhinav-harness/comment-plugin/internal/harness"
 	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/azure"
 	"github.com/drone/go-scm/scm/driver/bitbucket"
 	"github.com/drone/go-scm/scm/driver/gitea"
 	"github.com/drone/go-scm/scm/driver/github"
@@ -29,18 +31,74 @@ const (
 	ProviderGogs            Provider = "gogs"
 	ProviderHarness         Provider = "harness"
 	ProviderAzureDevOps     Provider = "azure-devops"
+	ProviderGerrit          Provider = "gerrit"
 )
 
+// AuthMode selects how the token is sent to the provider
+type AuthMode string
+
+const (
+	AuthBearer AuthMode = "bearer" // Authorization: Bearer <token>
+	AuthBasic  AuthMode = "basic"  // Basic auth with Username and the token as password
+	AuthToken  AuthMode = "token"  // Authorization: token <token>, as accepted by Gitea and Gogs
+	AuthOAuth2 AuthMode = "oauth2" // OAuth2 access token
+)
+
+// NativeOnly reports whether the provider has no go-scm driver and is only
+// reached through its own client
+func (p Provider) NativeOnly() bool {
+	return p == ProviderGerrit
+}
+
 // ClientOptions holds options for creating an SCM client
 type ClientOptions struct {
 	Provider Provider
-	Endpoint string // Custom endpoint for self-hosted instances
-	Token    string // Authentication token
+	Endpoint string   // Custom endpoint for self-hosted instances
+	Token    string   // Authentication token
+	Username string   // Username for basic auth, e.g. with Bitbucket app passwords
+	AuthMode AuthMode // Defaults to the provider's usual scheme
 
 	// Harness-specific options
 	HarnessAccountID string
 	HarnessOrgID     string
 	HarnessProjectID string
+
+	// Azure DevOps-specific options
+	AzureOrganization string
+	AzureProject      string
+
+	// GitHubApp authenticates as a GitHub App installation instead of Token
+	GitHubApp *GitHubApp
+
+	// OAuth2 obtains and renews access tokens for Bitbucket Cloud and GitLab
+	OAuth2 *OAuth2
+}
+
+// ValidateAuth checks that the provider can send the token the way AuthMode
+// and Username ask for
+func ValidateAuth(opts ClientOptions) error {
+	switch opts.AuthMode {
+	case "", AuthBearer, AuthBasic, AuthToken, AuthOAuth2:
+	default:
+		return fmt.Errorf("unsupported auth mode: %s", opts.AuthMode)
+	}
+
+	if opts.AuthMode == AuthBasic && opts.Username == "" {
+		return fmt.Errorf("username required for basic auth")
+	}
+
+	switch opts.Provider {
+	case ProviderHarness:
+		// Harness Code tokens are always sent as an API key
+		if opts.AuthMode != "" || opts.Username != "" {
+			return fmt.Errorf("auth mode and username are not supported for %s", opts.Provider)
+		}
+	case ProviderAzureDevOps, ProviderGerrit:
+		if opts.AuthMode == AuthToken {
+			return fmt.Errorf("auth mode %s is not supported for %s", opts.AuthMode, opts.Provider)
+		}
+	}
+	return nil
 }
 
 // NewClient creates a new SCM client based on the provider
@@ -48,6 +106,10 @@ func NewClient(opts ClientOptions) (*scm.Client, error) {
 	var client *scm.Client
 	var err error
 
+	if err := ValidateAuth(opts); err != nil {
+		return nil, err
+	}
+
 	switch opts.Provider {
 	case ProviderGitHub:
 		client = github.NewDefault()
@@ -80,10 +142,19 @@ func NewClient(opts ClientOptions) (*scm.Client, error) {
 		}
 		client, err = gogs.New(opts.Endpoint)
 	case ProviderHarness:
-		// Harness Code uses a custom client - we'll wrap it
-		client, err = newHarnessClient(opts)
+		// Harness Code authenticates in its own client
+		return newHarnessClient(opts)
 	case ProviderAzureDevOps:
-		return nil, fmt.Errorf("Azure DevOps is not yet supported by go-scm, use harness wrapper")
+		if opts.AzureOrganization == "" || opts.AzureProject == "" {
+			return nil, fmt.Errorf("organization and project required for Azure DevOps")
+		}
+		endpoint := opts.Endpoint
+		if endpoint == "" {
+			endpoint = "https://dev.azure.com"
+		}
+		client, err = azure.New(endpoint, opts.AzureOrganization, opts.AzureProject)
+	case ProviderGerrit:
+		return nil, fmt.Errorf("no go-scm driver for %s, use the native client", opts.Provider)
 	default:
 		return nil, fmt.Errorf("unsupported SCM provider: %s", opts.Provider)
 	}
@@ -92,6 +163,33 @@ func NewClient(opts ClientOptions) (*scm.Client, error) {
 		return nil, fmt.Errorf("failed to create SCM client: %w", err)
 	}
 
+	// GitHub Apps authenticate with short-lived installation tokens
+	if opts.GitHubApp != nil {
+		if opts.Provider != ProviderGitHub && opts.Provider != ProviderGitHubEnterprise {
+			return nil, fmt.Errorf("GitHub App authentication is only supported for GitHub")
+		}
+		source, err := newAppTokenSource(*opts.GitHubApp, client.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		client.Client = &http.Client{Transport: &oauth2.Transport{Source: source}}
+		return client, nil
+	}
+
+	// OAuth2 flows renew access tokens that expire during long runs
+	if opts.OAuth2 != nil {
+		tokenURL, err := oauthTokenURL(opts.Provider, *opts.OAuth2, client.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		source, err := newOAuthTokenSource(*opts.OAuth2, tokenURL, opts.Token)
+		if err != nil {
+			return nil, err
+		}
+		client.Client = &http.Client{Transport: &oauthTransport{source: source, base: http.DefaultTransport}}
+		return client, nil
+	}
+
 	// Configure authentication
 	if opts.Token != "" {
 		client.Client = configureAuth(client.Client, opts)
@@ -106,38 +204,59 @@ func configureAuth(httpClient *http.Client, opts ClientOptions) *http.Client {
 		httpClient = &http.Client{}
 	}
 
-	switch opts.Provider {
-	case ProviderBitbucket:
-		// Bitbucket Cloud uses OAuth2
+	switch authMode(opts) {
+	case AuthOAuth2:
 		httpClient.Transport = &oauth2.Transport{
 			Source: oauth2.StaticTokenSource(&scm.Token{Token: opts.Token}),
 		}
-	case ProviderBitbucketServer:
-		// Bitbucket Server uses Bearer token
-		httpClient.Transport = &transport.BearerToken{Token: opts.Token}
+	case AuthBasic:
+		// The token is the password, e.g. a Bitbucket app password
+		httpClient.Transport = &transport.BasicAuth{Username: opts.Username, Password: opts.Token}
+	case AuthToken:
+		httpClient.Transport = &transport.Authorization{Scheme: "token", Credentials: opts.Token}
 	default:
-		// Most providers use Bearer token
 		httpClient.Transport = &transport.BearerToken{Token: opts.Token}
 	}
 
 	return httpClient
 }
 
-// newHarnessClient creates a client configured for Harness Code
-func newHarnessClient(opts ClientOptions) (*scm.Client, error) {
-	endpoint := opts.Endpoint
-	if endpoint == "" {
-		endpoint = "https://app.harness.io/gateway/code/api/v1"
+// authMode returns the configured auth mode, or the provider's usual one.
+// A username implies basic auth.
+func authMode(opts ClientOptions) AuthMode {
+	if opts.AuthMode != "" {
+		return opts.AuthMode
+	}
+	if opts.Username != "" {
+		return AuthBasic
 	}
 
-	// Harness Code API is compatible with a subset of GitHub API
-	// but requires special headers and path modifications
-	client, err := github.New(endpoint)
-	if err != nil {
-		return nil, err
+	switch opts.Provider {
+	case ProviderBitbucket:
+		// Bitbucket Cloud uses OAuth2
+		return AuthOAuth2
+	case ProviderAzureDevOps:
+		// Azure DevOps personal access tokens are sent as the basic auth password
+		return AuthBasic
+	default:
+		// Most providers, including Bitbucket Server, use Bearer token
+		return AuthBearer
 	}
+}
 
-	return client, nil
+// newHarnessClient creates a go-scm client backed by the Harness Code client
+func newHarnessClient(opts ClientOptions) (*scm.Client, error) {
+	client, err := harness.NewClient(harness.Config{
+		Endpoint:  opts.Endpoint,
+		Token:     opts.Token,
+		AccountID: opts.HarnessAccountID,
+		OrgID:     opts.HarnessOrgID,
+		ProjectID: opts.HarnessProjectID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Harness client: %w", err)
+	}
+	return harness.NewSCMClient(client)
 }
 
 // ParseRepo parses a repository string into owner and repo name
@@ -160,6 +279,8 @@ func SupportedProviders() []Provider {
 		ProviderGitea,
 		ProviderGogs,
 		ProviderHarness,
+		ProviderAzureDevOps,
+		ProviderGerrit,
 	}
 }
 
@@ -0,0 +1,78 @@
+package scm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigureAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ClientOptions
+		want string
+	}{
+		{"github bearer", ClientOptions{Provider: ProviderGitHub}, "Bearer secret"},
+		{"bitbucket oauth2", ClientOptions{Provider: ProviderBitbucket}, "Bearer secret"},
+		{"azure basic", ClientOptions{Provider: ProviderAzureDevOps}, "Basic OnNlY3JldA=="},
+		{"username implies basic", ClientOptions{Provider: ProviderBitbucket, Username: "user"}, "Basic dXNlcjpzZWNyZXQ="},
+		{"token header", ClientOptions{Provider: ProviderGitea, AuthMode: AuthToken}, "token secret"},
+		{"explicit bearer with username", ClientOptions{Provider: ProviderGogs, Username: "user", AuthMode: AuthBearer}, "Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+			}))
+			defer srv.Close()
+
+			tt.opts.Token = "secret"
+			client := configureAuth(nil, tt.opts)
+			res, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientRejectsUnknownAuthMode(t *testing.T) {
+	if _, err := NewClient(ClientOptions{Provider: ProviderGitHub, Token: "t", AuthMode: "digest"}); err == nil {
+		t.Error("NewClient should reject an unknown auth mode")
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ClientOptions
+		wantErr bool
+	}{
+		{"provider default", ClientOptions{Provider: ProviderGitHub}, false},
+		{"basic with username", ClientOptions{Provider: ProviderAzureDevOps, AuthMode: AuthBasic, Username: "user"}, false},
+		{"basic without username", ClientOptions{Provider: ProviderBitbucket, AuthMode: AuthBasic}, true},
+		{"harness auth mode", ClientOptions{Provider: ProviderHarness, AuthMode: AuthBearer}, true},
+		{"harness username", ClientOptions{Provider: ProviderHarness, Username: "user"}, true},
+		{"azure token header", ClientOptions{Provider: ProviderAzureDevOps, AuthMode: AuthToken}, true},
+		{"gerrit bearer", ClientOptions{Provider: ProviderGerrit, AuthMode: AuthBearer}, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateAuth(tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateAuth error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewClientValidatesHarnessAuth(t *testing.T) {
+	if _, err := NewClient(ClientOptions{Provider: ProviderHarness, Token: "t", AuthMode: "digest"}); err == nil {
+		t.Error("NewClient should reject an unknown auth mode for Harness Code")
+	}
+}
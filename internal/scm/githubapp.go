@@ -0,0 +1,167 @@
+package scm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+)
+
+// installationTokenMargin is how long before expiry an installation token is
+// replaced, so a request never starts with a token about to expire
+const installationTokenMargin = 5 * time.Minute
+
+// GitHubApp identifies a GitHub App installation. Requests are authenticated
+// with installation tokens, so comments are authored by the app's bot user.
+type GitHubApp struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte // PEM encoded RSA key
+}
+
+// appTokenSource exchanges a signed app JWT for installation tokens and
+// replaces them shortly before they expire. It is safe for concurrent use.
+type appTokenSource struct {
+	app      GitHubApp
+	key      *rsa.PrivateKey
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+
+	mu    sync.Mutex
+	token *scm.Token
+}
+
+func newAppTokenSource(app GitHubApp, baseURL *url.URL) (*appTokenSource, error) {
+	if app.AppID == 0 || app.InstallationID == 0 {
+		return nil, fmt.Errorf("GitHub App ID and installation ID are required")
+	}
+	key, err := parsePrivateKey(app.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := baseURL.Parse(fmt.Sprintf("app/installations/%d/access_tokens", app.InstallationID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &appTokenSource{
+		app:      app,
+		key:      key,
+		endpoint: endpoint.String(),
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Token returns the current installation token, requesting a new one when
+// there is none or it expires soon
+func (s *appTokenSource) Token(ctx context.Context) (*scm.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.now().Add(installationTokenMargin).Before(s.token.Expires) {
+		return s.token, nil
+	}
+
+	token, err := s.installationToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub App installation token: %w", err)
+	}
+	s.token = token
+	return token, nil
+}
+
+func (s *appTokenSource) installationToken(ctx context.Context) (*scm.Token, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", res.StatusCode, string(body))
+	}
+
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &scm.Token{Token: out.Token, Expires: out.ExpiresAt}, nil
+}
+
+// jwt signs a short-lived RS256 token identifying the app. The issue time is
+// backdated to allow for clock drift, as GitHub recommends.
+func (s *appTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": fmt.Sprint(s.app.AppID),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey reads a PKCS#1 or PKCS#8 PEM encoded RSA key. Keys passed
+// through environment variables often have escaped newlines, which are
+// restored first.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	data = []byte(strings.ReplaceAll(string(data), `\n`, "\n"))
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,141 @@
+package scm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return key, pem.EncodeToMemory(block)
+}
+
+func TestAppJWT(t *testing.T) {
+	key, keyPEM := testKey(t)
+	base, _ := url.Parse("https://api.github.com/")
+
+	source, err := newAppTokenSource(GitHubApp{AppID: 42, InstallationID: 7, PrivateKey: keyPEM}, base)
+	if err != nil {
+		t.Fatalf("newAppTokenSource failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	source.now = func() time.Time { return now }
+
+	jwt, err := source.jwt()
+	if err != nil {
+		t.Fatalf("jwt failed: %v", err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt has %d parts, want 3", len(parts))
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("jwt signature does not verify: %v", err)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	_ = json.Unmarshal(payload, &claims)
+	if claims["iss"] != "42" || claims["iat"] != float64(now.Unix()-60) || claims["exp"] != float64(now.Unix()+540) {
+		t.Errorf("unexpected claims %v", claims)
+	}
+}
+
+func TestAppTokenSourceRefreshes(t *testing.T) {
+	_, keyPEM := testKey(t)
+
+	now := time.Unix(1700000000, 0)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v3/app/installations/7/access_tokens" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("missing JWT authorization")
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, requests, now.Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer srv.Close()
+
+	base, _ := url.Parse(srv.URL + "/api/v3/")
+	source, err := newAppTokenSource(GitHubApp{AppID: 42, InstallationID: 7, PrivateKey: keyPEM}, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if token.Token != "ghs_1" {
+			t.Errorf("token = %q, want cached ghs_1", token.Token)
+		}
+	}
+
+	// Within the margin before expiry a new token is requested
+	now = now.Add(56 * time.Minute)
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token.Token != "ghs_2" || requests != 2 {
+		t.Errorf("token = %q after %d requests, want a refreshed ghs_2", token.Token, requests)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, keyPEM := testKey(t)
+
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkcs8PEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	escaped := []byte(strings.ReplaceAll(string(keyPEM), "\n", `\n`))
+
+	for name, data := range map[string][]byte{"pkcs1": keyPEM, "pkcs8": pkcs8PEM, "escaped newlines": escaped} {
+		if _, err := parsePrivateKey(data); err != nil {
+			t.Errorf("%s: parsePrivateKey failed: %v", name, err)
+		}
+	}
+
+	if _, err := parsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("parsePrivateKey should reject non-PEM data")
+	}
+}
+
+func TestNewClientGitHubApp(t *testing.T) {
+	_, keyPEM := testKey(t)
+	app := &GitHubApp{AppID: 1, InstallationID: 2, PrivateKey: keyPEM}
+
+	if _, err := NewClient(ClientOptions{Provider: ProviderGitHub, GitHubApp: app}); err != nil {
+		t.Errorf("NewClient failed: %v", err)
+	}
+	if _, err := NewClient(ClientOptions{Provider: ProviderGitLab, GitHubApp: app}); err == nil {
+		t.Error("NewClient should reject GitHub App authentication for GitLab")
+	}
+}
@@ -0,0 +1,240 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+)
+
+// OAuth2 grant types used to obtain access tokens
+const (
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// accessTokenMargin is how long before expiry an OAuth2 access token is
+// replaced, so a request never starts with a token about to expire
+const accessTokenMargin = time.Minute
+
+// bitbucketTokenURL is the Bitbucket Cloud token endpoint
+const bitbucketTokenURL = "https://bitbucket.org/site/oauth2/access_token"
+
+// OAuth2 configures how access tokens are obtained and renewed for providers
+// whose tokens expire. ClientOptions.Token, when set, is used until the
+// first renewal.
+type OAuth2 struct {
+	GrantType    string // refresh_token or client_credentials
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	TokenURL     string // Defaults to the provider's token endpoint
+	Scopes       []string
+}
+
+// oauthTokenSource requests access tokens from the token endpoint and keeps
+// the newest one, along with the refresh token when the provider rotates it.
+// It is safe for concurrent use.
+type oauthTokenSource struct {
+	config   OAuth2
+	tokenURL string
+	client   *http.Client
+	now      func() time.Time
+
+	mu    sync.Mutex
+	token *scm.Token
+}
+
+func newOAuthTokenSource(config OAuth2, tokenURL string, initial string) (*oauthTokenSource, error) {
+	switch config.GrantType {
+	case GrantRefreshToken:
+		if config.RefreshToken == "" {
+			return nil, fmt.Errorf("refresh token required for the %s grant", config.GrantType)
+		}
+	case GrantClientCredentials:
+		if config.ClientID == "" || config.ClientSecret == "" {
+			return nil, fmt.Errorf("client ID and secret required for the %s grant", config.GrantType)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OAuth2 grant type: %s", config.GrantType)
+	}
+
+	s := &oauthTokenSource{
+		config:   config,
+		tokenURL: tokenURL,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}
+	if initial != "" {
+		// The expiry of a supplied token is unknown, so it is used until
+		// the provider rejects it
+		s.token = &scm.Token{Token: initial, Refresh: config.RefreshToken}
+	}
+	return s, nil
+}
+
+// oauthTokenURL returns the token endpoint for the provider, which may be
+// overridden by the OAuth2 configuration
+func oauthTokenURL(provider Provider, config OAuth2, baseURL *url.URL) (string, error) {
+	if config.TokenURL != "" {
+		return config.TokenURL, nil
+	}
+	switch provider {
+	case ProviderBitbucket:
+		return bitbucketTokenURL, nil
+	case ProviderGitLab:
+		endpoint, err := baseURL.Parse("oauth/token")
+		if err != nil {
+			return "", err
+		}
+		return endpoint.String(), nil
+	default:
+		return "", fmt.Errorf("OAuth2 authentication is only supported for Bitbucket Cloud and GitLab")
+	}
+}
+
+// Token returns the current access token, requesting a new one when there is
+// none or it expires soon
+func (s *oauthTokenSource) Token(ctx context.Context) (*scm.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && (s.token.Expires.IsZero() || s.now().Add(accessTokenMargin).Before(s.token.Expires)) {
+		return s.token, nil
+	}
+
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth2 access token: %w", err)
+	}
+	s.token = token
+	return token, nil
+}
+
+// invalidate drops the token when it is still the current one, so the next
+// call to Token requests a new one
+func (s *oauthTokenSource) invalidate(token *scm.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+func (s *oauthTokenSource) accessToken(ctx context.Context) (*scm.Token, error) {
+	values := url.Values{}
+	values.Set("grant_type", s.config.GrantType)
+	if s.config.GrantType == GrantRefreshToken {
+		values.Set("refresh_token", s.config.RefreshToken)
+	}
+	if len(s.config.Scopes) > 0 {
+		values.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if s.config.ClientSecret == "" && s.config.ClientID != "" {
+		// Public clients identify themselves in the body
+		values.Set("client_id", s.config.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(s.config.ClientID, s.config.ClientSecret)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", res.StatusCode, string(body))
+	}
+
+	var out struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	// Providers such as GitLab rotate the refresh token on every use
+	if out.RefreshToken != "" && s.config.GrantType == GrantRefreshToken {
+		s.config.RefreshToken = out.RefreshToken
+	}
+
+	token := &scm.Token{Token: out.AccessToken, Refresh: s.config.RefreshToken}
+	if out.ExpiresIn > 0 {
+		token.Expires = s.now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// oauthTransport authorizes requests with tokens from the source. A request
+// rejected with 401 is retried once with a new token, covering tokens that
+// expire earlier than announced or whose expiry is unknown.
+type oauthTransport struct {
+	source *oauthTokenSource
+	base   http.RoundTripper
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(authorize(req, token.Token))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The body was consumed and cannot be sent again
+		return res, nil
+	}
+
+	t.source.invalidate(token)
+	renewed, err := t.source.Token(req.Context())
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if renewed.Token == token.Token {
+		return res, nil
+	}
+	res.Body.Close()
+
+	retry := authorize(req, renewed.Token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// authorize returns a copy of the request with a bearer token, leaving the
+// original untouched as RoundTripper requires
+func authorize(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
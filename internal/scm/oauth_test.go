@@ -0,0 +1,155 @@
+package scm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOAuthTokenSourceRefreshToken(t *testing.T) {
+	var grants []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		grants = append(grants, r.PostForm)
+		if user, pass, ok := r.BasicAuth(); !ok || user != "id" || pass != "secret" {
+			t.Errorf("client credentials not sent with basic auth")
+		}
+		n := len(grants)
+		fmt.Fprintf(w, `{"access_token": "access-%d", "refresh_token": "refresh-%d", "expires_in": 7200}`, n, n)
+	}))
+	defer srv.Close()
+
+	config := OAuth2{GrantType: GrantRefreshToken, ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh-0"}
+	source, err := newOAuthTokenSource(config, srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token.Token != "access-1" {
+		t.Errorf("token = %q, want access-1", token.Token)
+	}
+
+	// Cached until shortly before expiry, then renewed with the rotated refresh token
+	_, _ = source.Token(context.Background())
+	now = now.Add(2*time.Hour - 30*time.Second)
+	token, err = source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token.Token != "access-2" || len(grants) != 2 {
+		t.Fatalf("token = %q after %d requests, want access-2 after 2", token.Token, len(grants))
+	}
+	if grants[0].Get("refresh_token") != "refresh-0" || grants[1].Get("refresh_token") != "refresh-1" {
+		t.Errorf("refresh tokens sent = %q, %q", grants[0].Get("refresh_token"), grants[1].Get("refresh_token"))
+	}
+	if grants[0].Get("grant_type") != "refresh_token" {
+		t.Errorf("grant_type = %q", grants[0].Get("grant_type"))
+	}
+}
+
+func TestOAuthTokenSourceClientCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "pullrequest repository" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		fmt.Fprint(w, `{"access_token": "access", "expires_in": 3600}`)
+	}))
+	defer srv.Close()
+
+	config := OAuth2{GrantType: GrantClientCredentials, ClientID: "id", ClientSecret: "secret", Scopes: []string{"pullrequest", "repository"}}
+	source, err := newOAuthTokenSource(config, srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := source.Token(context.Background())
+	if err != nil || token.Token != "access" {
+		t.Errorf("Token = %v, %v", token, err)
+	}
+
+	if _, err := newOAuthTokenSource(OAuth2{GrantType: GrantClientCredentials, ClientID: "id"}, srv.URL, ""); err == nil {
+		t.Error("client_credentials without a secret should fail")
+	}
+	if _, err := newOAuthTokenSource(OAuth2{GrantType: "password"}, srv.URL, ""); err == nil {
+		t.Error("unknown grant type should fail")
+	}
+}
+
+func TestOAuthTransportRetriesUnauthorized(t *testing.T) {
+	tokenRequests := 0
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		fmt.Fprint(w, `{"access_token": "fresh"}`)
+	}))
+	defer tokenSrv.Close()
+
+	var bodies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer api.Close()
+
+	source, err := newOAuthTokenSource(OAuth2{GrantType: GrantRefreshToken, RefreshToken: "r"}, tokenSrv.URL, "expired")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &oauthTransport{source: source, base: http.DefaultTransport}}
+
+	res, err := client.Post(api.URL, "application/json", strings.NewReader(`{"body":"hi"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want 201 after retry", res.StatusCode)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want 1", tokenRequests)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"body":"hi"}` {
+		t.Errorf("retried bodies = %q", bodies)
+	}
+}
+
+func TestOAuthTokenURL(t *testing.T) {
+	base, _ := url.Parse("https://gitlab.example.com/gitlab/")
+
+	tests := []struct {
+		provider Provider
+		config   OAuth2
+		want     string
+	}{
+		{ProviderBitbucket, OAuth2{}, bitbucketTokenURL},
+		{ProviderGitLab, OAuth2{}, "https://gitlab.example.com/gitlab/oauth/token"},
+		{ProviderGitLab, OAuth2{TokenURL: "https://sso.example.com/token"}, "https://sso.example.com/token"},
+	}
+	for _, tt := range tests {
+		got, err := oauthTokenURL(tt.provider, tt.config, base)
+		if err != nil || got != tt.want {
+			t.Errorf("oauthTokenURL(%s) = %q, %v, want %q", tt.provider, got, err, tt.want)
+		}
+	}
+
+	if _, err := oauthTokenURL(ProviderGitHub, OAuth2{}, base); err == nil {
+		t.Error("oauthTokenURL should reject providers without a default endpoint")
+	}
+}
@@ -0,0 +1,29 @@
+package scm
+
+import "strings"
+
+// Owners are CODEOWNERS owners sorted by kind
+type Owners struct {
+	Users  []string // @user, without the @
+	Teams  []string // @org/team, the team slug only
+	Emails []string
+}
+
+// SplitOwners sorts CODEOWNERS owners into users, teams and emails. Users
+// and teams are never nil, so they encode as JSON arrays.
+func SplitOwners(owners []string) Owners {
+	split := Owners{Users: []string{}, Teams: []string{}}
+	for _, owner := range owners {
+		name, ok := strings.CutPrefix(owner, "@")
+		switch {
+		case !ok:
+			split.Emails = append(split.Emails, owner)
+		case strings.Contains(name, "/"):
+			_, team, _ := strings.Cut(name, "/")
+			split.Teams = append(split.Teams, team)
+		default:
+			split.Users = append(split.Users, name)
+		}
+	}
+	return split
+}
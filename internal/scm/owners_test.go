@@ -0,0 +1,23 @@
+package scm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOwners(t *testing.T) {
+	got := SplitOwners([]string{"@alice", "@acme/backend", "sec@example.com", "@bob"})
+	want := Owners{
+		Users:  []string{"alice", "bob"},
+		Teams:  []string{"backend"},
+		Emails: []string{"sec@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitOwners = %+v, want %+v", got, want)
+	}
+
+	// Empty lists still encode as JSON arrays
+	if empty := SplitOwners(nil); empty.Users == nil || empty.Teams == nil {
+		t.Errorf("SplitOwners(nil) = %+v, want empty users and teams", empty)
+	}
+}
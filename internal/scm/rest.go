@@ -0,0 +1,111 @@
+package scm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+)
+
+// APIError is returned when a provider responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// HasStatus reports whether err is an API error with the status code
+func HasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// Unsupported reports whether the API rejected the endpoint itself rather
+// than the request content
+func Unsupported(err error) bool {
+	return HasStatus(err, http.StatusNotFound) ||
+		HasStatus(err, http.StatusMethodNotAllowed) ||
+		HasStatus(err, http.StatusNotImplemented)
+}
+
+// Rejected reports whether the API refused the request content, such as a
+// review comment on a line outside the diff
+func Rejected(err error) bool {
+	return HasStatus(err, http.StatusBadRequest) ||
+		HasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// REST calls provider REST endpoints that go-scm does not cover. It reuses
+// the go-scm client's base URL and authenticated transport.
+type REST struct {
+	client *scm.Client
+	accept string
+	log    *logrus.Entry
+}
+
+// NewREST creates a REST client on top of a go-scm client. accept is the
+// media type requested from the API.
+func NewREST(client *scm.Client, accept string, log *logrus.Entry) *REST {
+	return &REST{
+		client: client,
+		accept: accept,
+		log:    log,
+	}
+}
+
+// Do sends in as the JSON body of the request, when set, and decodes the JSON
+// response into out, when set. A non-2xx response is returned as an
+// *APIError.
+func (r *REST) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: http.Header{
+			"Accept": {r.accept},
+		},
+	}
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = bytes.NewReader(body)
+		r.log.WithField("payload", string(body)).Debug("request payload")
+	}
+
+	r.log.WithFields(logrus.Fields{
+		"method": method,
+		"path":   path,
+	}).Debug("making API request")
+
+	res, err := r.client.Do(ctx, req)
+	if err != nil {
+		r.log.WithError(err).Error("API request failed")
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.Status < 200 || res.Status > 299 {
+		body, _ := io.ReadAll(res.Body)
+		r.log.WithFields(logrus.Fields{
+			"status_code":   res.Status,
+			"response_body": string(body),
+		}).Error("API request returned error")
+		return &APIError{StatusCode: res.Status, Body: string(body)}
+	}
+
+	if out != nil {
+		return json.NewDecoder(res.Body).Decode(out)
+	}
+	return nil
+}
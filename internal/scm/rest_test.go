@@ -0,0 +1,66 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRESTDo(t *testing.T) {
+	// Suppress logging during tests
+	logrus.SetLevel(logrus.PanicLevel)
+
+	var accept, contentType string
+	var in map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`not found`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 7}`))
+	}))
+	defer srv.Close()
+
+	base, _ := url.Parse(srv.URL + "/")
+	rest := NewREST(&scm.Client{BaseURL: base}, "application/vnd.test+json", logrus.NewEntry(logrus.StandardLogger()))
+
+	var out struct {
+		ID int `json:"id"`
+	}
+	if err := rest.Do(context.Background(), http.MethodPost, "items", map[string]string{"name": "x"}, &out); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if out.ID != 7 || in["name"] != "x" {
+		t.Errorf("sent %v and decoded %+v", in, out)
+	}
+	if accept != "application/vnd.test+json" || contentType != "application/json" {
+		t.Errorf("headers Accept=%q Content-Type=%q", accept, contentType)
+	}
+
+	err := rest.Do(context.Background(), http.MethodGet, "missing", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Body != "not found" {
+		t.Fatalf("Do error = %v, want a 404 APIError", err)
+	}
+	if !Unsupported(err) || !HasStatus(fmt.Errorf("wrapped: %w", err), http.StatusNotFound) {
+		t.Error("a 404 should be unsupported, also when wrapped")
+	}
+	if Unsupported(&APIError{StatusCode: http.StatusUnprocessableEntity}) || Unsupported(errors.New("network")) {
+		t.Error("only endpoint errors are unsupported")
+	}
+	if !Rejected(fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusUnprocessableEntity})) || Rejected(err) {
+		t.Error("only content errors are rejected")
+	}
+}